@@ -0,0 +1,81 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"flag"
+	"fmt"
+)
+
+// Command is a subcommand, e.g. "commit" in "git commit", with a flag set
+// of its own.
+type Command struct {
+	// Name is the name of the subcommand as given on the command line.
+	Name string
+
+	// Summary briefly describes the subcommand. It's listed in the
+	// "Commands" section of the parent's help screen.
+	Summary string
+
+	// Flags are the flags of the subcommand.
+	Flags *Flags
+}
+
+// AddCommand adds a subcommand and returns its flag set, on which the
+// subcommand's flags are defined just as on the parent's. The subcommand
+// has the same help flag and error handling mode as the parent and its
+// command name is the parent's followed by name, e.g. "tool start".
+func (f *Flags) AddCommand(name, summary string) *Flags {
+	sub := NewFlagsWithErrorHandling(name, "", "", "", f.helpFlagName, f.PrintAllDefaults, f.ErrorHandling())
+	sub.CmdNameFunc = func() string {
+		return f.commandName() + " " + name
+	}
+	sub.SetOutput(f.Output())
+	sub.parent = f
+	f.commands = append(f.commands, &Command{name, summary, sub})
+	return sub
+}
+
+// Commands returns the subcommands in the order in which they were added.
+func (f *Flags) Commands() []*Command {
+	return append([]*Command(nil), f.commands...)
+}
+
+// ParseArgs parses the flags of the parent from arguments, which should
+// not include the command name, and then those of the subcommand named by
+// the first remaining argument, i.e. "[options] <command> [options]". It
+// returns the flag set of the subcommand, or that of the parent if no
+// subcommand was given or none were added.
+//
+// If the help flag is given, the help screen of the returned flag set is
+// printed and flag.ErrHelp is handled as per the error handling mode. An
+// unknown subcommand is handled like any other parse error.
+func (f *Flags) ParseArgs(arguments []string) (*Flags, error) {
+	if err := f.Parse(arguments); err != nil {
+		return f, err
+	}
+	if f.AskingHelp() || f.NArg() == 0 || len(f.commands) == 0 {
+		if f.HandleHelp() {
+			return f, f.fail(flag.ErrHelp)
+		}
+		return f, nil
+	}
+
+	name := f.Arg(0)
+	for _, c := range f.commands {
+		if c.Name != name {
+			continue
+		}
+		if err := c.Flags.Parse(f.Args()[1:]); err != nil {
+			return c.Flags, err
+		}
+		if c.Flags.HandleHelp() {
+			return c.Flags, c.Flags.fail(flag.ErrHelp)
+		}
+		return c.Flags, nil
+	}
+	return f, f.fail(fmt.Errorf("unknown command %q", name))
+}
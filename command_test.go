@@ -0,0 +1,91 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"bytes"
+	"flag"
+	"html"
+	"strings"
+	"testing"
+)
+
+func TestCommands(t *testing.T) {
+	newFlags := func(out *bytes.Buffer) (*Flags, *Flags, *int) {
+		flags := NewFlagsWithErrorHandling("pping", "pping - Protocol Ping", "", "", "help", false, flag.ContinueOnError)
+		flags.SetOutput(out)
+		flags.Bool("v", false, "Verbose output.")
+		ping := flags.AddCommand("ping", "Ping a server.")
+		ping.UsageOptions = "[options] host port"
+		count := ping.Int("c", 0, "Stop after sending specified `num`ber of pings.")
+		flags.AddCommand("scan", "Scan the ports of a server, like a port scanner would.")
+		return flags, ping, count
+	}
+
+	var out bytes.Buffer
+	flags, ping, count := newFlags(&out)
+	exp := "pping - Protocol Ping\n" +
+		"Usage: pping <command> [options]\n" +
+		"\n" +
+		"Commands:\n" +
+		"  ping  Ping a server.\n" +
+		"  scan  Scan the ports of a server, like a port scanner would.\n" +
+		"\n" +
+		"Options:\n" +
+		"  -v   Verbose output.\n"
+	compare(t, exp, flags.HelpText())
+	compare(t, 2, len(flags.Commands()))
+
+	got, err := flags.ParseArgs([]string{"-v", "ping", "-c", "3", "example.com", "80"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, ping, got)
+	compare(t, 3, *count)
+	compare(t, "example.com 80", strings.Join(got.Args(), " "))
+
+	flags, ping, _ = newFlags(&out)
+	got, err = flags.ParseArgs([]string{"ping", "-help"})
+	compare(t, flag.ErrHelp, err)
+	compare(t, ping, got)
+	exp = "Usage: pping ping [options] host port\n" +
+		"\n" +
+		"Options:\n" +
+		"  -c num  Stop after sending specified number of pings.\n"
+	compare(t, exp, out.String())
+
+	out.Reset()
+	flags, _, _ = newFlags(&out)
+	got, err = flags.ParseArgs([]string{"trace"})
+	compare(t, flags, got)
+	compare(t, "unknown command \"trace\"", err.Error())
+	compare(t, "unknown command \"trace\"\nUsage: pping <command> [options]\nSee 'pping -help'\n", out.String())
+}
+
+func TestCommandNames(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.AddCommand("100%", "Ping at full rate.")
+	flags.AddCommand("a&b", "Ping a and b.")
+
+	exp := "Usage: pping <command> [options]\n" +
+		"\n" +
+		"Commands:\n" +
+		"  100%  Ping at full rate.\n" +
+		"  a&b   Ping a and b.\n" +
+		"\n" +
+		"Options:\n"
+	compare(t, exp, flags.HelpText())
+
+	flags.Sanitizer = html.EscapeString
+	exp = "Usage: pping &lt;command&gt; [options]\n" +
+		"\n" +
+		"Commands:\n" +
+		"  100%     Ping at full rate.\n" +
+		"  a&amp;b  Ping a and b.\n" +
+		"\n" +
+		"Options:\n"
+	compare(t, exp, flags.HelpText())
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// nonIdentifier matches the characters that can't be used in the name of a
+// shell function.
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// GenBashCompletion writes a bash completion script for the command to w,
+// which completes the flag names, and the allowed values of enum flags
+// (see Enum), e.g. to be saved in /etc/bash_completion.d. Subcommands (see
+// AddCommand) are completed along with their own flags, so the script is
+// always registered for the top-level command, even when it's generated
+// from the flag set of a subcommand.
+func (f *Flags) GenBashCompletion(w io.Writer) error {
+	for f.parent != nil {
+		f = f.parent
+	}
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("# bash completion for %s\n", f.commandName()))
+	f.bashCompletion(&buf)
+	buf.WriteString(fmt.Sprintf("complete -F %s %s\n", f.bashFunc(), f.commandName()))
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// bashCompletion writes the completion function of the flag set followed
+// by those of its subcommands. The function of a subcommand is given the
+// index of the word following the subcommand's name, from which it looks
+// for its own subcommands, and once a subcommand is given, its function
+// completes the rest of the line.
+func (f *Flags) bashCompletion(buf *bytes.Buffer) {
+	var names, takesValue []string
+	var values bytes.Buffer
+	f.VisitAll(func(fl *flag.Flag) {
+		// The values of hidden flags must be skipped all the same when
+		// looking for the subcommand.
+		if bv, ok := fl.Value.(boolFlag); !ok || !bv.IsBoolFlag() {
+			takesValue = append(takesValue, "-"+fl.Name, "--"+fl.Name)
+		}
+		if f.hidden[fl.Name] {
+			return
+		}
+		names = append(names, f.dash(fl.Name))
+		if e, ok := fl.Value.(*enumValue); ok {
+			values.WriteString(fmt.Sprintf("        %s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")); return;;\n",
+				f.dash(fl.Name), strings.Join(e.allowed, " ")))
+		}
+	})
+
+	buf.WriteString(fmt.Sprintf("%s() {\n", f.bashFunc()))
+	buf.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	if len(f.commands) > 0 {
+		// The subcommand is the first word that is neither a flag nor
+		// the value of one, which may be given as -name=value.
+		if f.parent == nil {
+			buf.WriteString("    local i=1\n")
+		} else {
+			buf.WriteString("    local i=$1\n")
+		}
+		buf.WriteString("    while [ \"$i\" -lt \"$COMP_CWORD\" ]; do\n")
+		buf.WriteString("        case \"${COMP_WORDS[i]}\" in\n")
+		if len(takesValue) > 0 {
+			buf.WriteString(fmt.Sprintf("            %s) [ \"${COMP_WORDS[i+1]}\" = \"=\" ] && i=$((i+3)) || i=$((i+2));;\n",
+				strings.Join(takesValue, "|")))
+		}
+		buf.WriteString("            =) i=$((i+2));;\n")
+		buf.WriteString("            -*) i=$((i+1));;\n")
+		buf.WriteString("            *) break;;\n")
+		buf.WriteString("        esac\n")
+		buf.WriteString("    done\n")
+		buf.WriteString("    if [ \"$i\" -lt \"$COMP_CWORD\" ]; then\n")
+		buf.WriteString("        case \"${COMP_WORDS[i]}\" in\n")
+		for _, c := range f.commands {
+			buf.WriteString(fmt.Sprintf("            %s) %s $((i+1)); return;;\n", c.Name, c.Flags.bashFunc()))
+			names = append(names, c.Name)
+		}
+		buf.WriteString("        esac\n")
+		buf.WriteString("    fi\n")
+	}
+	if values.Len() > 0 {
+		buf.WriteString("    case \"${COMP_WORDS[COMP_CWORD-1]}\" in\n")
+		buf.Write(values.Bytes())
+		buf.WriteString("    esac\n")
+	}
+	buf.WriteString(fmt.Sprintf("    COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", strings.Join(names, " ")))
+	buf.WriteString("}\n")
+
+	for _, c := range f.commands {
+		c.Flags.bashCompletion(buf)
+	}
+}
+
+// bashFunc returns the name of the bash completion function of the flag
+// set, e.g. "_pping_start" for the subcommand "pping start".
+func (f *Flags) bashFunc() string {
+	return "_" + nonIdentifier.ReplaceAllString(f.commandName(), "_")
+}
+
+// PowerShellCompletion returns a PowerShell script registering an
+// argument completer for the command, which offers the flags along with
+// the first line of their descriptions as tooltips.
+func (f *Flags) PowerShellCompletion() string {
+	quote := func(s string) string {
+		return "'" + strings.Replace(s, "'", "''", -1) + "'"
+	}
+
+	var buf bytes.Buffer
+	write := func(msg string, args ...interface{}) {
+		buf.WriteString(fmt.Sprintf(msg, args...))
+	}
+
+	write("Register-ArgumentCompleter -Native -CommandName %s -ScriptBlock {\n", quote(f.commandName()))
+	write("    param($wordToComplete, $commandAst, $cursorPosition)\n")
+	write("    @(\n")
+	f.VisitAll(func(fl *flag.Flag) {
+		if f.hidden[fl.Name] {
+			return
+		}
+		name := f.dash(fl.Name)
+		_, usage := f.describe(fl)
+		tip := strings.TrimSpace(strings.Split(unescapeNewlines(usage), "\n")[0])
+		if tip == "" {
+			tip = name
+		}
+		write("        [System.Management.Automation.CompletionResult]::new(%s, %s, 'ParameterName', %s)\n",
+			quote(name), quote(name), quote(tip))
+	})
+	write("    ) | Where-Object { $_.CompletionText -like \"$wordToComplete*\" }\n")
+	write("}\n")
+	return buf.String()
+}
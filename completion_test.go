@@ -0,0 +1,125 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPowerShellCompletion(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Int("c", 0, "Stop after sending specified `num`ber of pings.")
+	flags.String("p", "tcp", "Specify `protocol` to use `default`:\n- tcp\n- udp")
+	flags.Bool("w", false, "Wait for the server's response.")
+	flags.Bool("x", false, "")
+
+	got := flags.PowerShellCompletion()
+	for _, exp := range []string{
+		"Register-ArgumentCompleter -Native -CommandName 'pping' -ScriptBlock {\n",
+		"::new('-c', '-c', 'ParameterName', 'Stop after sending specified number of pings.')\n",
+		"::new('-help', '-help', 'ParameterName', 'Help screen.')\n",
+		"::new('-p', '-p', 'ParameterName', 'Specify protocol to use (default=tcp):')\n",
+		"::new('-w', '-w', 'ParameterName', 'Wait for the server''s response.')\n",
+		"::new('-x', '-x', 'ParameterName', '-x')\n",
+	} {
+		if !strings.Contains(got, exp) {
+			t.Errorf("completion doesn't contain %q:\n%s", exp, got)
+		}
+	}
+}
+
+func TestGenBashCompletion(t *testing.T) {
+	flags := NewFlags("/usr/bin/p-ping", "", "", "", "help", false)
+	flags.Int("c", 0, "Stop after sending specified `num`ber of pings.")
+	flags.String("protocol", "tcp", "Specify `protocol` to use.")
+	flags.Bool("w", false, "Wait for a response.")
+
+	exp := "# bash completion for p-ping\n" +
+		"_p_ping() {\n" +
+		"    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n" +
+		"    COMPREPLY=($(compgen -W \"-c -help -protocol -w\" -- \"$cur\"))\n" +
+		"}\n" +
+		"complete -F _p_ping p-ping\n"
+	var buf bytes.Buffer
+	if err := flags.GenBashCompletion(&buf); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, exp, buf.String())
+}
+
+func TestGenBashCompletionEnum(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Enum("p", "tcp", []string{"tcp", "udp"}, "Specify `protocol` to use.")
+	flags.Bool("w", false, "Wait for a response.")
+	flags.Bool("debug", false, "Print debugging information.")
+	flags.Hide("debug")
+
+	exp := "# bash completion for pping\n" +
+		"_pping() {\n" +
+		"    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n" +
+		"    case \"${COMP_WORDS[COMP_CWORD-1]}\" in\n" +
+		"        -p) COMPREPLY=($(compgen -W \"tcp udp\" -- \"$cur\")); return;;\n" +
+		"    esac\n" +
+		"    COMPREPLY=($(compgen -W \"-help -p -w\" -- \"$cur\"))\n" +
+		"}\n" +
+		"complete -F _pping pping\n"
+	var buf bytes.Buffer
+	if err := flags.GenBashCompletion(&buf); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, exp, buf.String())
+}
+
+func TestGenBashCompletionCommands(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Bool("v", false, "Verbose output.")
+	flags.Int("t", 5, "Timeout in `seconds`.")
+	ping := flags.AddCommand("ping", "Ping a host.")
+	ping.Enum("p", "tcp", []string{"tcp", "udp"}, "Specify `protocol` to use.")
+	flags.AddCommand("scan", "Scan the ports of a host.")
+
+	exp := "# bash completion for pping\n" +
+		"_pping() {\n" +
+		"    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n" +
+		"    local i=1\n" +
+		"    while [ \"$i\" -lt \"$COMP_CWORD\" ]; do\n" +
+		"        case \"${COMP_WORDS[i]}\" in\n" +
+		"            -t|--t) [ \"${COMP_WORDS[i+1]}\" = \"=\" ] && i=$((i+3)) || i=$((i+2));;\n" +
+		"            =) i=$((i+2));;\n" +
+		"            -*) i=$((i+1));;\n" +
+		"            *) break;;\n" +
+		"        esac\n" +
+		"    done\n" +
+		"    if [ \"$i\" -lt \"$COMP_CWORD\" ]; then\n" +
+		"        case \"${COMP_WORDS[i]}\" in\n" +
+		"            ping) _pping_ping $((i+1)); return;;\n" +
+		"            scan) _pping_scan $((i+1)); return;;\n" +
+		"        esac\n" +
+		"    fi\n" +
+		"    COMPREPLY=($(compgen -W \"-help -t -v ping scan\" -- \"$cur\"))\n" +
+		"}\n" +
+		"_pping_ping() {\n" +
+		"    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n" +
+		"    case \"${COMP_WORDS[COMP_CWORD-1]}\" in\n" +
+		"        -p) COMPREPLY=($(compgen -W \"tcp udp\" -- \"$cur\")); return;;\n" +
+		"    esac\n" +
+		"    COMPREPLY=($(compgen -W \"-help -p\" -- \"$cur\"))\n" +
+		"}\n" +
+		"_pping_scan() {\n" +
+		"    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n" +
+		"    COMPREPLY=($(compgen -W \"-help\" -- \"$cur\"))\n" +
+		"}\n" +
+		"complete -F _pping pping\n"
+	for _, fs := range []*Flags{flags, ping} {
+		var buf bytes.Buffer
+		if err := fs.GenBashCompletion(&buf); err != nil {
+			t.Fatal(err)
+		}
+		compare(t, exp, buf.String())
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"bytes"
+	"strings"
+)
+
+// DiffHelp returns a line by line diff of the help screens of a and b, in
+// the style of a unified diff with the whole help screen as context: lines
+// only in a are prefixed with "-", lines only in b with "+" and common
+// lines with a space. It returns an empty string if the help screens are
+// identical. This is meant for reviewing changes to the command line
+// interface. The help screens are rendered as by WriteHelpFile, i.e.
+// without colors and wrapped to LineWidth, or 72 columns if it isn't set,
+// so that the diff doesn't depend on the terminal.
+func DiffHelp(a, b *Flags) string {
+	x := strings.Split(strings.TrimSuffix(a.helpText(a.fileWidth(), false), "\n"), "\n")
+	y := strings.Split(strings.TrimSuffix(b.helpText(b.fileWidth(), false), "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:]
+	// and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			switch {
+			case x[i] == y[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	changed := false
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			buf.WriteString(" " + x[i] + "\n")
+			i++
+			j++
+		case j == len(y) || (i < len(x) && lcs[i+1][j] >= lcs[i][j+1]):
+			buf.WriteString("-" + x[i] + "\n")
+			changed = true
+			i++
+		default:
+			buf.WriteString("+" + y[j] + "\n")
+			changed = true
+			j++
+		}
+	}
+	if !changed {
+		return ""
+	}
+	return "--- a\n+++ b\n" + buf.String()
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDiffHelp(t *testing.T) {
+	newFlags := func() *Flags {
+		flags := NewFlags("pping", "", "", "[options] host", "help", false)
+		flags.Int("c", 0, "Stop after sending specified `num`ber of pings.")
+		flags.Bool("w", false, "Wait for a response.")
+		return flags
+	}
+	a := newFlags()
+	b := newFlags()
+	compare(t, "", DiffHelp(a, b))
+
+	b.Int("s", 64, "Payload `size` in bytes `default`.")
+	exp := "--- a\n" +
+		"+++ b\n" +
+		" Usage: pping [options] host\n" +
+		" \n" +
+		" Options:\n" +
+		"-  -c num  Stop after sending specified number of pings.\n" +
+		"-  -w      Wait for a response.\n" +
+		"+  -c num   Stop after sending specified number of pings.\n" +
+		"+  -s size  Payload size in bytes (default=64).\n" +
+		"+  -w       Wait for a response.\n"
+	compare(t, exp, DiffHelp(a, b))
+}
+
+func TestDiffHelpTerminal(t *testing.T) {
+	// The diff is the same whether or not the output is a terminal
+	defer func(fn func(io.Writer) bool) { isTerminal = fn }(isTerminal)
+	isTerminal = func(w io.Writer) bool { return true }
+	defer os.Setenv("NO_COLOR", os.Getenv("NO_COLOR"))
+	os.Unsetenv("NO_COLOR")
+
+	a := NewFlags("pping", "", "", "[options] host", "help", false)
+	a.Color = true
+	a.LineWidth = 40
+	a.Bool("w", false, "Wait for a response from the server before sending the next ping.")
+	b := NewFlags("pping", "", "", "[options] host", "help", false)
+	b.Color = true
+	b.LineWidth = 40
+
+	exp := "--- a\n" +
+		"+++ b\n" +
+		" Usage: pping [options] host\n" +
+		" \n" +
+		" Options:\n" +
+		"-  -w   Wait for a response from the\n" +
+		"-       server before sending the next\n" +
+		"-       ping.\n"
+	got := DiffHelp(a, b)
+	compare(t, exp, got)
+	if strings.Contains(got, "\x1b") {
+		t.Errorf("colors used in the diff:\n%q", got)
+	}
+}
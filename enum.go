@@ -0,0 +1,81 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// enumValue is the flag.Value of an enum flag. It only accepts the allowed
+// values.
+type enumValue struct {
+	value   *string
+	allowed []string
+}
+
+func (e *enumValue) String() string {
+	if e == nil || e.value == nil {
+		return ""
+	}
+	return *e.value
+}
+
+func (e *enumValue) Set(s string) error {
+	for _, a := range e.allowed {
+		if s == a {
+			*e.value = s
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(e.allowed, ", "))
+}
+
+// Enum defines a string flag that only accepts the allowed values, e.g.
+// "tcp" or "udp" for a protocol. Unless the usage mentions all the allowed
+// values as words of their own, they are appended to it, e.g.
+// "(one of: tcp, udp)". The back-quoted parameter name and `default` work
+// as with any other flag.
+func (f *Flags) Enum(name, def string, allowed []string, usage string) *string {
+	listed := true
+	for _, a := range allowed {
+		if !containsWord(usage, a) {
+			listed = false
+			break
+		}
+	}
+	if !listed {
+		usage += " (one of: " + strings.Join(allowed, ", ") + ")"
+	}
+
+	p := new(string)
+	*p = def
+	f.VarTracked(&enumValue{p, append([]string(nil), allowed...)}, name, usage)
+	return p
+}
+
+// containsWord returns true if s contains word, other than as part of a
+// longer word, e.g. "udp" in "tcp or udp" but not in "udp6".
+func containsWord(s, word string) bool {
+	isWordRune := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+	}
+	for offset := 0; ; {
+		i := strings.Index(s[offset:], word)
+		if i == -1 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(word)
+		before, _ := utf8.DecodeLastRuneInString(s[:start])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if (start == 0 || !isWordRune(before)) && (end == len(s) || !isWordRune(after)) {
+			return true
+		}
+		offset = start + 1
+	}
+}
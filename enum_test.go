@@ -0,0 +1,62 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+)
+
+func TestEnum(t *testing.T) {
+	newFlags := func() (*Flags, *string, *string) {
+		flags := NewFlags("pping", "", "", "", "help", false)
+		flags.Init("pping", flag.ContinueOnError)
+		flags.SetOutput(ioutil.Discard)
+		protocol := flags.Enum("p", "tcp", []string{"tcp", "udp"}, "Specify `protocol` to use `default`.")
+		format := flags.Enum("f", "", []string{"text", "json"}, "Output `format`: text or json.")
+		return flags, protocol, format
+	}
+
+	flags, protocol, format := newFlags()
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -f format    Output format: text or json.\n" +
+		"  -p protocol  Specify protocol to use (default=tcp). (one of: tcp, udp)\n"
+	compare(t, exp, flags.HelpText())
+	compare(t, "tcp", *protocol)
+	compare(t, "", *format)
+
+	if err := flags.Parse([]string{"-p", "udp", "-f", "json"}); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, "udp", *protocol)
+	compare(t, "json", *format)
+
+	flags, protocol, _ = newFlags()
+	err := flags.Parse([]string{"-p", "tpc"})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	compare(t, `invalid value "tpc" for flag -p: must be one of tcp, udp`, err.Error())
+	compare(t, "tcp", *protocol)
+}
+
+func TestEnumListed(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Enum("f", "", []string{"text", "json"}, "Output as json or text.")
+	flags.Enum("p", "", []string{"tcp", "udp"}, "Use tcp or udp6.")
+	flags.Enum("v", "", []string{"4", "6"}, "IP version 64.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -f   Output as json or text.\n" +
+		"  -p   Use tcp or udp6. (one of: tcp, udp)\n" +
+		"  -v   IP version 64. (one of: 4, 6)\n"
+	compare(t, exp, flags.HelpText())
+}
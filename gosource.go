@@ -0,0 +1,117 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// flagTypes maps the flag.Value types of the flag package to the names of
+// the types they hold.
+var flagTypes = map[string]string{
+	"*flag.boolValue":     "bool",
+	"*flag.intValue":      "int",
+	"*flag.int64Value":    "int64",
+	"*flag.uintValue":     "uint",
+	"*flag.uint64Value":   "uint64",
+	"*flag.stringValue":   "string",
+	"*flag.float64Value":  "float64",
+	"*flag.durationValue": "duration",
+}
+
+// exportedTypes maps the numeric type names returned by flagType to the
+// names of the functions defining them in the flag package.
+var exportedTypes = map[string]string{
+	"int":     "Int",
+	"int64":   "Int64",
+	"uint":    "Uint",
+	"uint64":  "Uint64",
+	"float64": "Float64",
+}
+
+// flagType returns the name of the type held by the flag (e.g. "int") or
+// an empty string if the flag wasn't defined with one of the typed
+// functions of the flag package.
+func flagType(fl *flag.Flag) string {
+	return flagTypes[reflect.TypeOf(fl.Value).String()]
+}
+
+// GoSource returns Go source code, in package pkg, for a function named
+// newFlags that reconstructs this flag set: the NewFlags call, the
+// version flag (see EnableVersion), the examples and the registration of
+// every flag with its default and usage. The command name is the one
+// resolved by CmdNameFunc, if set, at the time GoSource is called.
+// Flags defined with custom values (i.e. with Var) can't be reconstructed
+// and are noted with a comment instead.
+func (f *Flags) GoSource(pkg string) string {
+	var body bytes.Buffer
+	write := func(msg string, args ...interface{}) {
+		body.WriteString(fmt.Sprintf(msg, args...))
+	}
+
+	q := strconv.Quote
+	usesTime := false
+
+	write("flags := niceflags.NewFlags(%s, %s, %s, %s, %s, %v)\n",
+		q(f.commandName()), q(f.Title), q(f.Description), q(f.UsageOptions), q(f.helpFlagName), f.PrintAllDefaults)
+	if f.versionFlagName != "" {
+		write("flags.EnableVersion(%s, %s)\n", q(f.versionFlagName), q(f.version))
+	}
+	if len(f.Examples) > 0 {
+		write("flags.Examples = []string{\n")
+		for _, e := range f.Examples {
+			write("%s,\n", q(e))
+		}
+		write("}\n")
+	}
+
+	f.VisitAll(func(fl *flag.Flag) {
+		if fl.Name == f.helpFlagName || (f.versionFlagName != "" && fl.Name == f.versionFlagName) {
+			return
+		}
+		name, usage := q(fl.Name), q(fl.Usage)
+		switch typ := flagType(fl); typ {
+		case "":
+			write("// -%s (%T) must be registered with flags.Var\n", fl.Name, fl.Value)
+		case "bool":
+			write("flags.Bool(%s, %s, %s)\n", name, fl.DefValue, usage)
+		case "string":
+			write("flags.String(%s, %s, %s)\n", name, q(fl.DefValue), usage)
+		case "duration":
+			usesTime = true
+			d, _ := time.ParseDuration(fl.DefValue)
+			write("flags.Duration(%s, time.Duration(%d), %s)\n", name, int64(d), usage)
+		default:
+			write("flags.%s(%s, %s, %s)\n", exportedTypes[typ], name, fl.DefValue, usage)
+		}
+	})
+	write("return flags\n")
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by niceflags. DO NOT EDIT.\n\n")
+	buf.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	buf.WriteString("import (\n")
+	if usesTime {
+		buf.WriteString("\"time\"\n\n")
+	}
+	buf.WriteString("\"github.com/codeliveroil/niceflags\"\n)\n\n")
+	buf.WriteString(fmt.Sprintf("// newFlags constructs the flag set of %s.\n", f.commandName()))
+	buf.WriteString("func newFlags() *niceflags.Flags {\n")
+	buf.Write(body.Bytes())
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return buf.String()
+	}
+	return string(src)
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoSource(t *testing.T) {
+	flags := NewFlags("pping", "pping - Protocol Ping", "Tool to simulate \"TCP\" pings.", "[options] host port", "help", false)
+	flags.Examples = []string{"-s 128 google.com 80"}
+	flags.Int("s", 64, "Payload `size` in bytes `default`.")
+	flags.String("p", "tcp", "Specify `protocol` to use.\n- tcp\n- udp")
+	flags.Bool("w", false, "Wait for a response.")
+	flags.Duration("t", 1500*time.Millisecond, "Max `time`-to-live.")
+	flags.Float64("r", 0.5, "Rate.")
+	flags.Var(&unitValue{}, "u", "Custom value.")
+
+	src := flags.GoSource("main")
+	if _, err := parser.ParseFile(token.NewFileSet(), "flags.go", src, 0); err != nil {
+		t.Fatalf("generated source doesn't parse: %v\n%s", err, src)
+	}
+	for _, exp := range []string{
+		"package main\n",
+		"\"time\"\n",
+		`flags := niceflags.NewFlags("pping", "pping - Protocol Ping", "Tool to simulate \"TCP\" pings.", "[options] host port", "help", false)`,
+		`"-s 128 google.com 80",`,
+		"flags.Int(\"s\", 64, \"Payload `size` in bytes `default`.\")",
+		"flags.String(\"p\", \"tcp\", \"Specify `protocol` to use.\\n- tcp\\n- udp\")",
+		`flags.Bool("w", false, "Wait for a response.")`,
+		"flags.Duration(\"t\", time.Duration(1500000000), \"Max `time`-to-live.\")",
+		`flags.Float64("r", 0.5, "Rate.")`,
+		"// -u (*niceflags.unitValue) must be registered with flags.Var",
+	} {
+		if !strings.Contains(src, exp) {
+			t.Errorf("generated source doesn't contain %q:\n%s", exp, src)
+		}
+	}
+	if strings.Contains(src, `flags.Bool("help"`) {
+		t.Errorf("generated source registers the help flag:\n%s", src)
+	}
+}
+
+func TestGoSourceVersion(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.CmdNameFunc = func() string { return "pping6" }
+	flags.EnableVersion("version", "v1.2")
+	flags.Bool("w", false, "Wait for a response.")
+
+	exp := "// Code generated by niceflags. DO NOT EDIT.\n" +
+		"\n" +
+		"package main\n" +
+		"\n" +
+		"import (\n" +
+		"\t\"github.com/codeliveroil/niceflags\"\n" +
+		")\n" +
+		"\n" +
+		"// newFlags constructs the flag set of pping6.\n" +
+		"func newFlags() *niceflags.Flags {\n" +
+		"\tflags := niceflags.NewFlags(\"pping6\", \"\", \"\", \"\", \"help\", false)\n" +
+		"\tflags.EnableVersion(\"version\", \"v1.2\")\n" +
+		"\tflags.Bool(\"w\", false, \"Wait for a response.\")\n" +
+		"\treturn flags\n" +
+		"}\n"
+	src := flags.GoSource("main")
+	compare(t, exp, src)
+	if _, err := parser.ParseFile(token.NewFileSet(), "flags.go", src, 0); err != nil {
+		t.Fatalf("generated source doesn't parse: %v\n%s", err, src)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+)
+
+// numberFormat holds the separators used to write numbers in a locale.
+type numberFormat struct {
+	group   string
+	decimal string
+}
+
+// numberFormats maps locales, or just their languages, to the way they
+// write numbers. It's a deliberately small subset of the CLDR data, so that
+// niceflags doesn't depend on golang.org/x/text. Regional variants are
+// looked up before the language.
+// Spaces separating thousands are non-breaking so that numbers aren't
+// wrapped.
+var numberFormats = map[string]numberFormat{
+	"en":    {",", "."},
+	"zh":    {",", "."},
+	"ja":    {",", "."},
+	"ko":    {",", "."},
+	"de":    {".", ","},
+	"de-ch": {"'", "."},
+	"es":    {".", ","},
+	"it":    {".", ","},
+	"nl":    {".", ","},
+	"pt":    {".", ","},
+	"da":    {".", ","},
+	"tr":    {".", ","},
+	"fr":    {"\u00a0", ","},
+	"fr-ch": {"'", "."},
+	"ru":    {"\u00a0", ","},
+	"pl":    {"\u00a0", ","},
+	"cs":    {"\u00a0", ","},
+	"sv":    {"\u00a0", ","},
+	"nb":    {"\u00a0", ","},
+	"fi":    {"\u00a0", ","},
+}
+
+// lookupNumberFormat returns the number format of a locale such as "de",
+// "de-DE" or "fr_CH".
+func lookupNumberFormat(locale string) (numberFormat, bool) {
+	locale = strings.ToLower(strings.Replace(locale, "_", "-", -1))
+	if nf, ok := numberFormats[locale]; ok {
+		return nf, true
+	}
+	nf, ok := numberFormats[strings.Split(locale, "-")[0]]
+	return nf, ok
+}
+
+// localize formats a numeric or duration default as per the number format
+// of a locale. Other defaults, including those of custom values, are
+// returned as is.
+func localize(fl *flag.Flag, def string, nf numberFormat) string {
+	if isInteger(fl) {
+		return groupThousands(def, nf.group)
+	}
+	switch flagType(fl) {
+	case "float64":
+		parts := strings.SplitN(def, ".", 2)
+		if _, err := strconv.ParseInt(parts[0], 10, 64); err != nil {
+			return def // e.g. exponents
+		}
+		if len(parts) == 1 {
+			return groupThousands(parts[0], nf.group)
+		}
+		return groupThousands(parts[0], nf.group) + nf.decimal + parts[1]
+	case "duration":
+		return strings.Replace(def, ".", nf.decimal, -1)
+	}
+	return def
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocale(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Locale = "de-DE"
+	flags.Int("c", 10000, "Stop after sending specified `num`ber of pings `default`.")
+	flags.Float64("r", 1234.5, "Pings per second `default`.")
+	flags.Duration("t", 1500*time.Millisecond, "Ping `timeout` `default`.")
+	flags.String("p", "1.5", "Payload `pattern` `default`.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -c num      Stop after sending specified number of pings\n" +
+		"              (default=10.000).\n" +
+		"  -p pattern  Payload pattern (default=1.5).\n" +
+		"  -r          Pings per second (default=1.234,5).\n" +
+		"  -t timeout  Ping timeout (default=1,5s).\n"
+	compare(t, exp, flags.HelpText())
+
+	flags.Locale = "fr_FR"
+	compare(t, "Stop after sending specified number of pings (default=10\u00a0000).", usageOf(flags, "c"))
+	flags.Locale = "xx"
+	compare(t, "Stop after sending specified number of pings (default=10000).", usageOf(flags, "c"))
+	flags.GroupDigits = true
+	compare(t, "Stop after sending specified number of pings (default=10,000).", usageOf(flags, "c"))
+	flags.Locale = "de"
+	compare(t, "Stop after sending specified number of pings (default=10.000).", usageOf(flags, "c"))
+}
+
+func usageOf(flags *Flags, name string) string {
+	_, usage := flags.describe(flags.Lookup(name))
+	return usage
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// roffEscaper escapes the characters that have a special meaning in groff
+// text.
+var roffEscaper = strings.NewReplacer(
+	`\`, `\e`,
+	"-", `\-`,
+)
+
+// roffEscape escapes text for groff, one line per line of s. Lines that
+// would otherwise be taken for requests are protected with a zero-width
+// character.
+func roffEscape(s string) string {
+	lines := strings.Split(unescapeNewlines(s), "\n")
+	for i, l := range lines {
+		l = roffEscaper.Replace(l)
+		if strings.HasPrefix(l, ".") || strings.HasPrefix(l, "'") {
+			l = `\&` + l
+		}
+		lines[i] = l
+	}
+	return strings.Join(lines, "\n")
+}
+
+// GenManPage writes the help screen as a man page, in groff markup, to w,
+// e.g. to be installed as pping.1 for section 1. Descriptions aren't
+// wrapped since man reflows them anyway.
+func (f *Flags) GenManPage(w io.Writer, section int) error {
+	var buf bytes.Buffer
+
+	write := func(msg string, args ...interface{}) {
+		buf.WriteString(fmt.Sprintf(msg, args...))
+	}
+
+	// paragraph writes text, keeping its line breaks.
+	paragraph := func(text string) {
+		write("%s\n", strings.Replace(roffEscape(stripNowrap(text)), "\n", "\n.br\n", -1))
+	}
+
+	cmd := f.commandName()
+	write(".TH %s %d\n", strings.ToUpper(roffEscape(cmd)), section)
+
+	// Name
+	write(".SH NAME\n")
+	switch {
+	case strings.HasPrefix(f.Title, cmd+" - "):
+		write("%s\n", roffEscape(f.Title))
+	case f.Title != "":
+		write("%s \\- %s\n", roffEscape(cmd), roffEscape(f.Title))
+	default:
+		write("%s\n", roffEscape(cmd))
+	}
+
+	// Command usage
+	usageTokens := strings.Split(unescapeNewlines(f.UsageOptions), "\n")
+	write(".SH SYNOPSIS\n.B %s\n", roffEscape(cmd))
+	if usageTokens[0] != "" {
+		write("%s\n", roffEscape(usageTokens[0]))
+	}
+	if l := len(usageTokens); l > 1 {
+		write(".PP\n")
+		paragraph(strings.Join(usageTokens[1:l], "\n"))
+	}
+
+	// Description
+	if f.Description != "" {
+		write(".SH DESCRIPTION\n")
+		paragraph(f.Description)
+	}
+
+	// Options
+	write(".SH OPTIONS\n")
+	f.VisitAll(func(fl *flag.Flag) {
+		if f.skip(fl) {
+			return
+		}
+		param, usage := f.describe(f.longFlag(fl))
+		write(".TP\n\\fB%s\\fR", roffEscape(f.dash(fl.Name)))
+		if param != "" {
+			write(" \\fI%s\\fR", roffEscape(param))
+		}
+		write("\n")
+		paragraph(usage)
+		if envVar, ok := f.EnvVar(fl.Name); ok {
+			write(".br\nEnvironment variable: \\fB%s\\fR\n", roffEscape(envVar))
+		}
+		if key, ok := f.ConfigKey(fl.Name); ok {
+			write(".br\nConfig key: \\fB%s\\fR\n", roffEscape(key))
+		}
+	})
+
+	// Examples
+	// Consecutive examples without a description are listed verbatim, one
+	// per line, and the others are tagged paragraphs.
+	if examples := f.examples(); len(examples) > 0 {
+		write(".SH EXAMPLES\n")
+		verbatim, tagged := false, false
+		for _, e := range examples {
+			cmd := roffEscape(f.withCmdName(e.Command))
+			if e.Desc == "" {
+				if !verbatim {
+					if tagged {
+						write(".PP\n")
+					}
+					write(".nf\n")
+					verbatim = true
+				}
+				write("%s\n", cmd)
+				continue
+			}
+			if verbatim {
+				write(".fi\n")
+				verbatim = false
+			}
+			write(".TP\n\\fB%s\\fR\n", cmd)
+			paragraph(e.Desc)
+			tagged = true
+		}
+		if verbatim {
+			write(".fi\n")
+		}
+	}
+
+	// Exit codes
+	if len(f.ExitCodes) > 0 {
+		write(".SH EXIT STATUS\n")
+		codes := make([]int, 0, len(f.ExitCodes))
+		for code := range f.ExitCodes {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			write(".TP\n.B %d\n", code)
+			paragraph(f.ExitCodes[code])
+		}
+	}
+
+	if stamp := f.stamp(); stamp != "" {
+		write(".PP\n%s\n", roffEscape(stamp))
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// stamp returns the line stamping generated documents, or an empty string
+// if Stamp isn't set.
+func (f *Flags) stamp() string {
+	if !f.Stamp {
+		return ""
+	}
+	now := time.Now
+	if f.Now != nil {
+		now = f.Now
+	}
+	name := f.commandName()
+	if f.version != "" {
+		name += " " + f.version
+	}
+	return fmt.Sprintf("Generated by %s on %s", name, now().Format("2006-01-02"))
+}
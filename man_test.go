@@ -0,0 +1,173 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenManPage(t *testing.T) {
+	flags := NewFlags(
+		"pping",
+		"pping - Protocol Ping",
+		"Tool to simulate TCP and UDP pings.\n.This line starts with a dot.",
+		"[options] host port",
+		"help",
+		false)
+	flags.Examples = []string{"-s 128 google.com 80"}
+	flags.ExitCodes = map[int]string{0: "All pings were answered.", 1: "Some pings timed out."}
+	flags.Int("s", 64, "Payload `size` in bytes `default`.")
+	flags.String("p", "tcp", "Specify `protocol` to use `default`:\n- tcp\n- udp")
+	flags.String("d", "", `DNS server, e.g. \\server.`)
+
+	exp := ".TH PPING 1\n" +
+		".SH NAME\n" +
+		"pping \\- Protocol Ping\n" +
+		".SH SYNOPSIS\n" +
+		".B pping\n" +
+		"[options] host port\n" +
+		".SH DESCRIPTION\n" +
+		"Tool to simulate TCP and UDP pings.\n" +
+		".br\n" +
+		"\\&.This line starts with a dot.\n" +
+		".SH OPTIONS\n" +
+		".TP\n" +
+		"\\fB\\-d\\fR\n" +
+		"DNS server, e.g. \\e\\eserver.\n" +
+		".TP\n" +
+		"\\fB\\-p\\fR \\fIprotocol\\fR\n" +
+		"Specify protocol to use (default=tcp):\n" +
+		".br\n" +
+		"\\- tcp\n" +
+		".br\n" +
+		"\\- udp\n" +
+		".TP\n" +
+		"\\fB\\-s\\fR \\fIsize\\fR\n" +
+		"Payload size in bytes (default=64).\n" +
+		".SH EXAMPLES\n" +
+		".nf\n" +
+		"pping \\-s 128 google.com 80\n" +
+		".fi\n" +
+		".SH EXIT STATUS\n" +
+		".TP\n" +
+		".B 0\n" +
+		"All pings were answered.\n" +
+		".TP\n" +
+		".B 1\n" +
+		"Some pings timed out.\n"
+	var buf bytes.Buffer
+	if err := flags.GenManPage(&buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, exp, buf.String())
+}
+
+func TestGenManPageExamplesDetailed(t *testing.T) {
+	flags := NewFlags("pping", "", "", "host", "help", false)
+	flags.Examples = []string{"google.com"}
+	flags.ExamplesDetailed = []Example{
+		{Command: "-s 128 google.com", Desc: "Ping with a larger payload."},
+		{Command: "-p udp google.com", Tags: []string{"advanced"}},
+		{Command: "-c 3 google.com", Tags: []string{"basic"}},
+	}
+	flags.ShowExampleTags = []string{"advanced"}
+
+	var buf bytes.Buffer
+	if err := flags.GenManPage(&buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	exp := ".SH EXAMPLES\n" +
+		".nf\n" +
+		"pping google.com\n" +
+		".fi\n" +
+		".TP\n" +
+		"\\fBpping \\-s 128 google.com\\fR\n" +
+		"Ping with a larger payload.\n" +
+		".PP\n" +
+		".nf\n" +
+		"pping \\-p udp google.com\n" +
+		".fi\n"
+	if got := buf.String(); !strings.HasSuffix(got, exp) {
+		t.Errorf("man page doesn't end with %q:\n%s", exp, got)
+	}
+}
+
+func TestGenManPageEnvAndConfigKey(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.ShowEnvInline = true
+	flags.String("host", "localhost", "Server `host`.")
+	flags.BindEnv("host", "PPING_HOST")
+	flags.SetConfigKey("host", "server.host")
+
+	var buf bytes.Buffer
+	if err := flags.GenManPage(&buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	exp := ".TP\n" +
+		"\\fB\\-host\\fR \\fIhost\\fR\n" +
+		"Server host.\n" +
+		".br\n" +
+		"Environment variable: \\fBPPING_HOST\\fR\n" +
+		".br\n" +
+		"Config key: \\fBserver.host\\fR\n"
+	if got := buf.String(); !strings.Contains(got, exp) {
+		t.Errorf("man page doesn't contain %q:\n%s", exp, got)
+	}
+}
+
+func TestStamp(t *testing.T) {
+	flags := NewFlags("pping", "", "", "host", "help", false)
+	flags.EnableVersion("version", "v1.2")
+	flags.Stamp = true
+	flags.Now = func() time.Time { return time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC) }
+
+	var buf bytes.Buffer
+	if err := flags.GenManPage(&buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	exp := ".TH PPING 1\n" +
+		".SH NAME\n" +
+		"pping\n" +
+		".SH SYNOPSIS\n" +
+		".B pping\n" +
+		"host\n" +
+		".SH OPTIONS\n" +
+		".PP\n" +
+		"Generated by pping v1.2 on 2024\\-01\\-02\n"
+	compare(t, exp, buf.String())
+
+	if md := flags.HelpMarkdown(); !strings.HasSuffix(md, "\n\nGenerated by pping v1.2 on 2024-01-02\n") {
+		t.Errorf("Markdown isn't stamped:\n%s", md)
+	}
+	if help := flags.HelpText(); strings.Contains(help, "Generated by") {
+		t.Errorf("help screen is stamped:\n%s", help)
+	}
+}
+
+func TestLongUsage(t *testing.T) {
+	flags := NewFlags("pping", "", "", "host", "help", false)
+	flags.Int("c", 3, "Stop after `num` pings.")
+	flags.SetLongUsage("c", "Stop after sending `num` pings `default`. Each ping waits for the previous one to be "+
+		"answered or to time out.")
+
+	var buf bytes.Buffer
+	if err := flags.GenManPage(&buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	long := "Stop after sending num pings (default=3). Each ping waits for the previous one to be answered or to time out."
+	if man := buf.String(); !strings.Contains(man, "\\fB\\-c\\fR \\fInum\\fR\n"+long+"\n") {
+		t.Errorf("man page doesn't use the long usage:\n%s", man)
+	}
+	if md := flags.HelpMarkdown(); !strings.Contains(md, "| Stop after sending `num` pings. Each ping waits") {
+		t.Errorf("Markdown doesn't use the long usage:\n%s", md)
+	}
+	if help := flags.HelpText(); !strings.Contains(help, "  -c num  Stop after num pings.\n") {
+		t.Errorf("help screen doesn't use the usage:\n%s", help)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// mdCellEscaper escapes text for a cell of a Markdown table.
+var mdCellEscaper = strings.NewReplacer(
+	"|", `\|`,
+	"\n", "<br>",
+)
+
+// HelpMarkdown returns the help screen as Markdown, e.g. for a reference
+// page of a documentation site. The flags are listed in a table, along with
+// their environment variables (see BindEnv) and configuration keys (see
+// SetConfigKey) if any flag has one, and the usage and examples are
+// rendered as code blocks. Descriptions aren't wrapped since Markdown
+// reflows them anyway.
+func (f *Flags) HelpMarkdown() string {
+	var buf bytes.Buffer
+
+	write := func(msg string, args ...interface{}) {
+		buf.WriteString(fmt.Sprintf(msg, args...))
+	}
+
+	code := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return "`" + s + "`"
+	}
+
+	// Title
+	if f.Title != "" {
+		write("# %s\n\n", f.Title)
+	}
+
+	// Description
+	if f.Description != "" {
+		write("%s\n\n", stripNowrap(unescapeNewlines(f.Description)))
+	}
+
+	// Command usage
+	usageTokens := strings.Split(unescapeNewlines(f.UsageOptions), "\n")
+	write("## Usage\n\n```\n%s\n```\n\n", f.withCmdName(usageTokens[0]))
+	if l := len(usageTokens); l > 1 {
+		write("%s\n\n", strings.Join(usageTokens[1:l], "\n"))
+	}
+
+	// Options
+	write("## Options\n\n")
+	// The environment variables and configuration keys are only listed if
+	// any flag has one.
+	withEnv, withKey := false, false
+	f.VisitAll(func(fl *flag.Flag) {
+		if f.skip(fl) {
+			return
+		}
+		_, env := f.EnvVar(fl.Name)
+		_, key := f.ConfigKey(fl.Name)
+		withEnv = withEnv || env
+		withKey = withKey || key
+	})
+	header, rule := "| Flag | Parameter | Default |", "| --- | --- | --- |"
+	if withEnv {
+		header, rule = header+" Environment |", rule+" --- |"
+	}
+	if withKey {
+		header, rule = header+" Config key |", rule+" --- |"
+	}
+	write("%s Description |\n%s --- |\n", header, rule)
+	f.VisitAll(func(fl *flag.Flag) {
+		if f.skip(fl) {
+			return
+		}
+		param, usage := f.describeDefault(f.longFlag(fl), false, true)
+		def, _ := f.displayDefault(fl)
+		write("| %s | %s | %s |",
+			code(f.dash(fl.Name)),
+			mdCellEscaper.Replace(code(param)),
+			mdCellEscaper.Replace(code(def)))
+		if withEnv {
+			envVar, _ := f.EnvVar(fl.Name)
+			write(" %s |", mdCellEscaper.Replace(code(envVar)))
+		}
+		if withKey {
+			key, _ := f.ConfigKey(fl.Name)
+			write(" %s |", mdCellEscaper.Replace(code(key)))
+		}
+		write(" %s |\n", mdCellEscaper.Replace(unescapeNewlines(usage)))
+	})
+	write("\n")
+
+	// Examples
+	// Consecutive examples without a description share a code block and
+	// the description of an example follows its own block.
+	if examples := f.examples(); len(examples) > 0 {
+		write("## Examples\n\n")
+		inBlock := false
+		for _, e := range examples {
+			if !inBlock {
+				write("```\n")
+				inBlock = true
+			}
+			write("%s\n", f.withCmdName(unescapeNewlines(e.Command)))
+			if e.Desc != "" {
+				write("```\n\n%s\n\n", stripNowrap(unescapeNewlines(e.Desc)))
+				inBlock = false
+			}
+		}
+		if inBlock {
+			write("```\n\n")
+		}
+	}
+
+	// Exit codes
+	if len(f.ExitCodes) > 0 {
+		write("## Exit Status\n\n")
+		write("| Code | Description |\n")
+		write("| --- | --- |\n")
+		codes := make([]int, 0, len(f.ExitCodes))
+		for code := range f.ExitCodes {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			write("| %d | %s |\n", code, mdCellEscaper.Replace(stripNowrap(unescapeNewlines(f.ExitCodes[code]))))
+		}
+		write("\n")
+	}
+
+	md := strings.TrimRight(buf.String(), "\n") + "\n"
+	if stamp := f.stamp(); stamp != "" {
+		md += "\n" + stamp + "\n"
+	}
+	return md
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHelpMarkdown(t *testing.T) {
+	flags := NewFlags(
+		"pping",
+		"pping - Protocol Ping",
+		"Tool to simulate TCP and UDP pings. This can also be used as a port scanner, which is handy to "+
+			"check firewall rules.",
+		"[options] host port",
+		"help",
+		false)
+	flags.Examples = []string{"-s 128 google.com 80"}
+	flags.Int("s", 64, "Payload `size` in bytes `default`.")
+	flags.String("p", "tcp", "Specify `protocol` to use `default`:\n- tcp\n- udp | udp6")
+	flags.Bool("w", false, "Wait for a response.")
+
+	exp := "# pping - Protocol Ping\n" +
+		"\n" +
+		"Tool to simulate TCP and UDP pings. This can also be used as a port scanner, which is handy to " +
+		"check firewall rules.\n" +
+		"\n" +
+		"## Usage\n" +
+		"\n" +
+		"```\n" +
+		"pping [options] host port\n" +
+		"```\n" +
+		"\n" +
+		"## Options\n" +
+		"\n" +
+		"| Flag | Parameter | Default | Description |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| `-p` | `protocol` | `tcp` | Specify `protocol` to use:<br>- tcp<br>- udp \\| udp6 |\n" +
+		"| `-s` | `size` | `64` | Payload `size` in bytes. |\n" +
+		"| `-w` |  |  | Wait for a response. |\n" +
+		"\n" +
+		"## Examples\n" +
+		"\n" +
+		"```\n" +
+		"pping -s 128 google.com 80\n" +
+		"```\n"
+	compare(t, exp, flags.HelpMarkdown())
+}
+
+func TestHelpMarkdownParam(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Duration("t", 0, "Wait some time for the `time`.")
+
+	if md := flags.HelpMarkdown(); !strings.Contains(md, "| `-t` | `time` |  | Wait some time for the `time`. |\n") {
+		t.Errorf("parameter isn't back-quoted where it is:\n%s", md)
+	}
+}
+
+func TestHelpMarkdownExamplesAndExitCodes(t *testing.T) {
+	flags := NewFlags("pping", "", "", "host", "help", false)
+	flags.Examples = []string{"google.com"}
+	flags.ExamplesDetailed = []Example{
+		{Command: "-s 128 google.com", Desc: "Ping with a larger payload."},
+		{Command: "-p udp google.com", Tags: []string{"advanced"}},
+		{Command: "-c 3 google.com", Tags: []string{"basic"}},
+	}
+	flags.ShowExampleTags = []string{"advanced"}
+	flags.ExitCodes = map[int]string{1: "Some pings timed out.", 0: "All pings were answered."}
+
+	exp := "## Examples\n" +
+		"\n" +
+		"```\n" +
+		"pping google.com\n" +
+		"pping -s 128 google.com\n" +
+		"```\n" +
+		"\n" +
+		"Ping with a larger payload.\n" +
+		"\n" +
+		"```\n" +
+		"pping -p udp google.com\n" +
+		"```\n" +
+		"\n" +
+		"## Exit Status\n" +
+		"\n" +
+		"| Code | Description |\n" +
+		"| --- | --- |\n" +
+		"| 0 | All pings were answered. |\n" +
+		"| 1 | Some pings timed out. |\n"
+	if md := flags.HelpMarkdown(); !strings.HasSuffix(md, exp) {
+		t.Errorf("Markdown doesn't end with %q:\n%s", exp, md)
+	}
+}
+
+func TestHelpMarkdownEnvAndConfigKey(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.String("host", "localhost", "Server `host`.")
+	flags.Bool("w", false, "Wait for a response.")
+	flags.BindEnv("host", "PPING_HOST")
+
+	md := flags.HelpMarkdown()
+	for _, exp := range []string{
+		"| Flag | Parameter | Default | Environment | Description |\n| --- | --- | --- | --- | --- |\n",
+		"| `-host` | `host` | `localhost` | `PPING_HOST` | Server `host`. |\n",
+		"| `-w` |  |  |  | Wait for a response. |\n",
+	} {
+		if !strings.Contains(md, exp) {
+			t.Errorf("Markdown doesn't contain %q:\n%s", exp, md)
+		}
+	}
+
+	flags.SetConfigKey("w", "server.wait")
+	exp := "| `-w` |  |  |  | `server.wait` | Wait for a response. |\n"
+	if md := flags.HelpMarkdown(); !strings.Contains(md, exp) {
+		t.Errorf("Markdown doesn't contain %q:\n%s", exp, md)
+	}
+}
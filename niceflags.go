@@ -9,14 +9,29 @@ import (
 	"bytes"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
+// maxLineLength is the line length used when the width of the terminal
+// can't be detected.
 const maxLineLength = 72
 
+// minDescriptionWidth is the width below which the flag descriptions
+// don't have room next to the flags. The options are then stacked, i.e.
+// every flag is listed on a line of its own with its description indented
+// below it.
+const minDescriptionWidth = 10
+
 // Flags defines the standard Flagset from
 // the flag package as well as some custom
 // fields.
@@ -44,14 +59,282 @@ type Flags struct {
 	// do not specify the command name in the examples.
 	Examples []string
 
+	// ExamplesDetailed defines examples that carry extra details. They are
+	// listed after Examples.
+	ExamplesDetailed []Example
+
+	// ShowExampleTags limits the detailed examples shown to the ones tagged
+	// with any of the given tags. Untagged examples are always shown and
+	// all examples are shown if this is empty.
+	ShowExampleTags []string
+
+	// ExamplePrompt is prepended to every example, e.g. "$ ".
+	ExamplePrompt string
+
+	// OmitCommandName leaves the command name out of the usage line and the
+	// examples, e.g. when it's already shown by the surrounding context.
+	OmitCommandName bool
+
+	// Related lists related commands, e.g. the other tools in a suite. They
+	// are listed in a "See also" section at the bottom of the help screen.
+	Related []string
+
+	// ExitCodes documents the exit codes of the application and their
+	// meanings. They are listed in an "Exit Status" section of the help
+	// screen.
+	ExitCodes map[int]string
+
 	// PrintAllDefaults prints the default value for a flag if the default
 	// value is not the Zero value. If this is set, it will override the
 	// back-quoted `default` option that may be embedded in the flag's
 	// usage.
 	PrintAllDefaults bool
 
-	helpFlagName string
-	cmdName      string
+	// InlineDefaults keeps the defaults printed by PrintAllDefaults at the
+	// end of the description rather than on a line of their own.
+	InlineDefaults bool
+
+	// DefaultFormat is the format used to render default values, whether
+	// they replace the back-quoted `default` literal or are printed by
+	// PrintAllDefaults. It must contain a single verb for the value and
+	// defaults to "(default=%v)".
+	DefaultFormat string
+
+	// ColumnSeparator separates the parameter column from the flag
+	// descriptions in the Options section. It defaults to two spaces.
+	ColumnSeparator string
+
+	// Indent is the number of spaces the sections of the help screen are
+	// indented by. It defaults to 2.
+	Indent int
+
+	// MinFlagColumn is the minimum width of the column of flag names (with
+	// their dashes) in the Options section, for a more spacious look when
+	// the flags are short.
+	MinFlagColumn int
+
+	// OptionsHeaderWithCount shows the number of flags listed in the
+	// heading of the Options section, e.g. "Options (12):".
+	OptionsHeaderWithCount bool
+
+	// MaxDescriptionChars truncates flag descriptions longer than the given
+	// number of characters in the help screen. Zero means no limit. Only
+	// the text of the usage is counted and cut, not the annotations
+	// appended to it (e.g. "(required)"), and generated documents such as
+	// the man page are never truncated.
+	MaxDescriptionChars int
+
+	// TruncationNote is appended to truncated flag descriptions, e.g.
+	// "... (see man page)". It defaults to an ellipsis when empty.
+	TruncationNote string
+
+	// DefaultsFromValue renders default values with the String method of
+	// the flag's Value rather than the string captured when the flag was
+	// defined. This helps custom values whose formatting is only complete
+	// once they're set up. Since the Value holds the parsed value after
+	// Parse, the help screen must be rendered before parsing.
+	DefaultsFromValue bool
+
+	// DefaultInParam renders non-Zero default values in the parameter
+	// column (e.g. "size=64") rather than in the description. Flags without
+	// a back-quoted parameter name keep their default in the description.
+	DefaultInParam bool
+
+	// GroupDigits separates the thousands of integer defaults with commas
+	// (e.g. "10,000,000") for readability. Only flags defined with Int,
+	// Int64, Uint or Uint64 are affected, so that e.g. a string default
+	// such as a ZIP code is left as is. Values given on the command line
+	// must still be written without separators.
+	GroupDigits bool
+
+	// Locale formats numeric and duration defaults the way they're written
+	// in the given locale, e.g. "de-DE" renders 10000 as "10.000" and 1.5
+	// as "1,5". It takes precedence over GroupDigits. To keep niceflags free
+	// of dependencies, only the number formats of a built-in set of common
+	// languages are known (en, zh, ja, ko, de, es, it, nl, pt, da, tr, fr,
+	// ru, pl, cs, sv, nb and fi, along with de-CH and fr-CH); other locales
+	// are ignored, as if Locale wasn't set, so GroupDigits still applies to
+	// them. Values given on the command line must still be written without
+	// separators and with a decimal point.
+	Locale string
+
+	// HelpOnNoArgs makes Parse print the help screen when the command is
+	// run without any arguments. Parse then handles flag.ErrHelp as per the
+	// error handling mode, i.e. the program exits with flag.ExitOnError.
+	HelpOnNoArgs bool
+
+	// PassthroughUnknown makes Parse collect unknown flags, rather than
+	// failing, so that they can be forwarded (e.g. to a sub-process). They
+	// are available from PassedThrough. Unknown keys given to ParseQuery
+	// are collected as well. Since niceflags can't tell whether an unknown
+	// flag takes a value, the argument following it is taken as its value
+	// unless it starts with a dash; use the -flag=value form to avoid any
+	// ambiguity.
+	PassthroughUnknown bool
+
+	// RejectExtraArgs makes Validate fail when more positional arguments
+	// are given than were declared with Positional.
+	RejectExtraArgs bool
+
+	// SortFlags is the order in which the flags are listed in the Options
+	// section.
+	SortFlags SortOrder
+
+	// RequiredFirst lists the flags marked as required with Require before
+	// the optional ones in the Options section and in every group, whatever
+	// the SortFlags order.
+	RequiredFirst bool
+
+	// ShowTypes appends the type of the value held by each flag (e.g.
+	// "[int]") to its description. This is independent of the back-quoted
+	// parameter name, which is free text. Flags defined with custom values
+	// aren't annotated.
+	ShowTypes bool
+
+	// ShowEnvInline appends the environment variable bound to a flag with
+	// BindEnv (e.g. "[env: PPING_HOST]") to its description in the help
+	// screen. Generated documents always list it apart.
+	ShowEnvInline bool
+
+	// KeepURLs places URLs (http:// and https://) that don't fit the
+	// current line on a line of their own, so they can be copied easily.
+	KeepURLs bool
+
+	// Hyperlinks renders the URLs (http:// and https://) of the help text as
+	// clickable links on terminals supporting OSC 8 hyperlinks, while still
+	// showing the URLs as is. Like colors, links are only used when the
+	// help text is written to a terminal.
+	Hyperlinks bool
+
+	// BulletMarker, if set, replaces the markers of list items ("- ", "* "
+	// or "• " at the start of a line) in descriptions so that lists look
+	// consistent. Wrapped list items are then indented under their text.
+	BulletMarker string
+
+	// PostProcess, if set, transforms the rendered help text before it's
+	// returned by HelpText (and so printed by PrintHelp), e.g. to insert
+	// links or replace tokens.
+	PostProcess func(string) string
+
+	// DoubleDash renders multi-character flags with two dashes (e.g.
+	// --protocol) and single character flags with one (e.g. -p) in all the
+	// generated output. The flag package accepts both forms when parsing.
+	DoubleDash bool
+
+	// Color highlights the section headings, flag names and parameter names
+	// of the help text with ANSI escape codes. Colors are only used when the
+	// help text is written to a terminal, i.e. the writer given to WriteHelp
+	// or, for PrintHelp and HelpText, the flag set's output, and the
+	// NO_COLOR environment variable isn't set. Files written by
+	// WriteHelpFile are never colored.
+	Color bool
+
+	// LineWidth is the width the help text is wrapped to. If it's zero,
+	// the width of the terminal is detected, falling back to the COLUMNS
+	// environment variable and then to 72 columns when stderr isn't a
+	// terminal. Files written by WriteHelpFile and the sections returned
+	// by HelpSections are wrapped to 72 columns unless LineWidth is set, so
+	// that they don't depend on the terminal they're generated in.
+	LineWidth int
+
+	// Wrapper, if set, replaces the built-in word wrapping. It's given the
+	// text and the width available to it and returns the wrapped lines,
+	// which are then indented by niceflags.
+	Wrapper func(text string, width int) []string
+
+	// Sanitizer, if set, escapes the user given text (title, descriptions,
+	// parameter names, examples, etc.) rendered by HelpText, e.g. to embed
+	// the help text in HTML. By default, the text is rendered as is.
+	Sanitizer func(string) string
+
+	// CmdNameFunc, if set, resolves the command name whenever it's
+	// rendered, e.g. for multi-call binaries that are invoked under several
+	// names. The name given to NewFlags is used otherwise.
+	CmdNameFunc func() string
+
+	// Stamp appends a line such as "Generated by pping v1.2 on 2024-01-02"
+	// to the man page and the Markdown generated from the flag set. The
+	// version is the one given to EnableVersion, if any. The help screen
+	// itself isn't stamped.
+	Stamp bool
+
+	// Now returns the date of the Stamp. It defaults to time.Now and can be
+	// set to a fixed date so that the generated documents are reproducible.
+	Now func() time.Time
+
+	helpFlagName  string
+	cmdName       string
+	presets       []preset
+	validators    []func() error
+	experimental  map[string]string
+	order         []string
+	passedThrough []string
+	meta          map[string]map[string]string
+	formatHints   map[string]string
+	longUsage     map[string]string
+	required      []string
+	groups        []*flagGroup
+	commands      []*Command
+	parent        *Flags
+	exclusive     [][]string
+	env           map[string]string
+	configKeys    map[string]string
+	implied       map[string]bool
+	hidden        map[string]bool
+	positional    []string
+
+	versionFlagName string
+	version         string
+
+	deprecated       map[string]string
+	warnedDeprecated map[string]bool
+}
+
+// flagGroup is a group of flags listed under its own heading.
+type flagGroup struct {
+	name  string
+	flags []string
+}
+
+func (g *flagGroup) has(name string) bool {
+	for _, n := range g.flags {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SortOrder is the order in which flags are listed.
+type SortOrder int
+
+const (
+	// SortDefault lists the flags in lexicographical order, as the flag
+	// package does, i.e. upper-case names come before lower-case ones.
+	SortDefault SortOrder = iota
+
+	// SortCaseInsensitive lists the flags in lexicographical order,
+	// ignoring case.
+	SortCaseInsensitive
+
+	// SortDeclared lists the flags in the order in which they were
+	// defined with the *Tracked functions (see Declared), followed by the
+	// other flags in lexicographical order.
+	SortDeclared
+)
+
+// Example is an example of the usage with extra details.
+type Example struct {
+	// Command is the example command line. Just as in Flags.Examples, do
+	// not specify the command name.
+	Command string
+
+	// Desc explains what the example does. It's printed below the command
+	// line.
+	Desc string
+
+	// Tags categorize the example, e.g. "basic" or "advanced".
+	Tags []string
 }
 
 // NewFlags constructs a new flag-set which can render cleaner help screen
@@ -75,17 +358,31 @@ type Flags struct {
 //   - The back-quoted literal `default` can be placed anywhere in the flag
 //     description/usage and niceflags will replace that with the non-Zero
 //     default value for the flag.
+//   - Phrases enclosed in {{nowrap}} and {{/nowrap}} are never broken
+//     across lines when descriptions are wrapped.
 func NewFlags(cmdName, title, description, usageOptions, helpFlagName string, printAllDefaults bool) *Flags {
+	return NewFlagsWithErrorHandling(cmdName, title, description, usageOptions, helpFlagName, printAllDefaults, flag.ExitOnError)
+}
+
+// NewFlagsWithErrorHandling is like NewFlags but lets the caller choose
+// how Parse handles errors, e.g. flag.ContinueOnError so that they are
+// returned rather than exiting the program.
+func NewFlagsWithErrorHandling(cmdName, title, description, usageOptions, helpFlagName string, printAllDefaults bool,
+	errorHandling flag.ErrorHandling) *Flags {
 	cmdName = path.Base(cmdName)
 	flags := &Flags{
-		flag.NewFlagSet(cmdName, flag.ExitOnError),
-		title, description, usageOptions, nil, printAllDefaults,
-		helpFlagName, cmdName,
+		FlagSet:          flag.NewFlagSet(cmdName, errorHandling),
+		Title:            title,
+		Description:      description,
+		UsageOptions:     usageOptions,
+		PrintAllDefaults: printAllDefaults,
+		helpFlagName:     helpFlagName,
+		cmdName:          cmdName,
 	}
-	flags.Bool(flags.helpFlagName, false, "Help screen.")
+	flags.BoolTracked(flags.helpFlagName, false, "Help screen.")
 
 	flags.Usage = func() {
-		PrintErr("See '%s -%s'\n", cmdName, helpFlagName)
+		fmt.Fprintf(flags.Output(), "%s\nSee '%s %s'\n", flags.Synopsis(), flags.commandName(), flags.dash(helpFlagName))
 	}
 	return flags
 }
@@ -102,156 +399,1007 @@ func (f *Flags) AskingHelp() bool {
 
 }
 
+// HelpFlagName returns the name of the flag that invokes the help screen,
+// without the leading dash.
+func (f *Flags) HelpFlagName() string {
+	return f.helpFlagName
+}
+
+// HelpRequestedIn returns true if the help flag is present in the given
+// raw arguments. Unlike AskingHelp, it doesn't require the arguments to be
+// parsed, so help can be shown even when other arguments are malformed.
+// Arguments following the "--" terminator are ignored.
+func (f *Flags) HelpRequestedIn(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			continue
+		}
+		name := strings.TrimPrefix(arg[1:], "-")
+		value := "true"
+		if i := strings.Index(name, "="); i != -1 {
+			name, value = name[:i], name[i+1:]
+		}
+		if name != f.helpFlagName {
+			continue
+		}
+		if v, err := strconv.ParseBool(value); err == nil && v {
+			return true
+		}
+	}
+	return false
+}
+
 // Help prints the help screen and exits if the help flag has
 // been invoked.
 func (f *Flags) Help() {
-	if f.AskingHelp() {
-		f.PrintHelp()
+	if f.HandleHelp() {
 		os.Exit(0)
 	}
 }
 
-// PrintHelp prints the help screen.
+// EnableVersion registers a flag, e.g. "version", which prints the version
+// of the application when invoked (on invocation of HandleVersion). Like
+// the help flag, it's left out of the Options section.
+func (f *Flags) EnableVersion(versionFlagName, version string) {
+	f.versionFlagName = versionFlagName
+	f.version = version
+	f.BoolTracked(versionFlagName, false, "Print the version.")
+}
+
+// HandleVersion prints the version to the flag set's output and exits if
+// the version flag has been invoked. Use ShowVersion to exit by other means.
+func (f *Flags) HandleVersion() {
+	if f.ShowVersion() {
+		os.Exit(0)
+	}
+}
+
+// ShowVersion prints the version to the flag set's output if the version
+// flag has been invoked and returns whether it did, leaving it up to the
+// caller to exit.
+func (f *Flags) ShowVersion() (shown bool) {
+	if f.versionFlagName == "" {
+		return false
+	}
+	fl := f.Lookup(f.versionFlagName)
+	if v, err := strconv.ParseBool(fl.Value.String()); err != nil || !v {
+		return false
+	}
+	fmt.Fprintln(f.Output(), f.version)
+	return true
+}
+
+// HandleHelp prints the help screen if the help flag has been invoked and
+// returns whether it did, leaving it up to the caller to exit.
+func (f *Flags) HandleHelp() (shown bool) {
+	if !f.AskingHelp() {
+		return false
+	}
+	f.PrintHelp()
+	return true
+}
+
+// PrintHelp prints the help screen to the flag set's output, which is
+// stderr unless changed with SetOutput (e.g. to os.Stdout).
 func (f *Flags) PrintHelp() {
-	PrintErr(sanitize(f.HelpText()))
+	f.WriteHelp(f.Output())
+}
+
+// WriteHelp writes the help screen to w. Unlike PrintErr, the text isn't
+// passed through a formatter, so % signs don't need to be escaped.
+func (f *Flags) WriteHelp(w io.Writer) error {
+	_, err := io.WriteString(w, f.helpText(f.lineWidth(), isTerminal(w)))
+	return err
+}
+
+// WriteHelpFile writes the help screen to the file at the given path,
+// creating any missing parent directories. It's wrapped to LineWidth, or
+// 72 columns if it isn't set, whatever the terminal. The file is written
+// to a temporary file first and then renamed, so readers never see a
+// partially written file.
+func (f *Flags) WriteHelpFile(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.WriteString(f.helpText(f.fileWidth(), false)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Synopsis returns the usage line of the help screen, e.g.
+// "Usage: pping [options] host port".
+func (f *Flags) Synopsis() string {
+	usage := strings.Split(unescapeNewlines(f.UsageOptions), "\n")[0]
+	if usage == "" && len(f.commands) > 0 {
+		usage = "<command> [options]"
+	}
+	return "Usage: " + f.withCmdName(usage)
 }
 
-// HelpText returns the help text.
-// % signs in any user given text is prefixed with another % (i.e. %%) so
-// that they are escaped if passed to a formatter like Printf or Sprintf.
+// withCmdName prefixes s with the command name unless OmitCommandName is
+// set.
+func (f *Flags) withCmdName(s string) string {
+	if f.OmitCommandName {
+		return s
+	}
+	return f.commandName() + " " + s
+}
+
+// commandName returns the command name as resolved by CmdNameFunc, if set.
+func (f *Flags) commandName() string {
+	if f.CmdNameFunc != nil {
+		return f.CmdNameFunc()
+	}
+	return f.cmdName
+}
+
+// HelpText returns the help text, as printed by PrintHelp. User given text
+// is escaped with the Sanitizer, if set.
 func (f *Flags) HelpText() string {
+	return f.helpText(f.lineWidth(), isTerminal(f.Output()))
+}
+
+// HelpTextSetOnly renders the Options section with only the flags that
+// were given on the command line, with their current values in place of
+// the parameter names, e.g. to summarize the user's choices after Parse.
+// Flags set by a preset or from the environment aren't listed.
+func (f *Flags) HelpTextSetOnly() string {
+	ansi := isTerminal(f.Output())
+	text := f.color(ansi, colorHeader, "Options:") + "\n" + f.link(ansi, f.options(f.Visit, f.isSet, f.lineWidth(), true, ansi))
+	if f.PostProcess != nil {
+		text = f.PostProcess(text)
+	}
+	return text
+}
+
+// HelpTextAtWidth returns the help text wrapped to the given width, e.g.
+// to repaint it as a terminal is resized. The flag set isn't modified.
+func (f *Flags) HelpTextAtWidth(width int) string {
+	return f.helpText(width, isTerminal(f.Output()))
+}
+
+// HelpTextWidth returns the help text wrapped to the given width. overflow
+// is true if any line is wider than that, e.g. because of a word that
+// can't be broken, so that callers can retry with a larger width.
+func (f *Flags) HelpTextWidth(width int) (text string, overflow bool) {
+	text = f.helpText(width, isTerminal(f.Output()))
+	for _, ln := range strings.Split(text, "\n") {
+		if textWidth(ln) > width {
+			return text, true
+		}
+	}
+	return text, false
+}
+
+// Section is a part of the help screen, such as the usage or the options.
+type Section struct {
+	// Name identifies the section: "Title", "Description", "Usage",
+	// "Commands", "Options", the name of a group of flags (see Group), "Examples",
+	// "Exit Status" or "See also".
+	Name string
+
+	// Lines are the rendered lines of the section, including its heading.
+	Lines []string
+}
+
+// HelpSections returns the sections of the help screen, in order, so that
+// they can be rendered independently. Sections without content are left
+// out. The sections are rendered as by WriteHelpFile, i.e. without colors
+// and wrapped to LineWidth, or 72 columns if it isn't set, whatever the
+// terminal: joined with blank lines between them, except after the title,
+// they make up the text of the help file, before PostProcess is applied.
+func (f *Flags) HelpSections() []Section {
+	return f.helpSections(f.fileWidth(), false)
+}
+
+// helpText renders the help text wrapped to lineLen characters. ANSI escape
+// codes are only used if ansi is set, i.e. the text is written to a
+// terminal.
+func (f *Flags) helpText(lineLen int, ansi bool) string {
+	var buf bytes.Buffer
+	var prev string
+	for i, sec := range f.helpSections(lineLen, ansi) {
+		if i > 0 && sec.Name != "Description" && prev != "Title" {
+			buf.WriteString("\n")
+		}
+		prev = sec.Name
+		for _, ln := range sec.Lines {
+			buf.WriteString(ln + "\n")
+		}
+	}
+
+	if f.PostProcess != nil {
+		return f.PostProcess(buf.String())
+	}
+	return buf.String()
+}
+
+// helpSections renders the sections of the help screen wrapped to lineLen
+// characters, with ANSI escape codes if ansi is set.
+func (f *Flags) helpSections(lineLen int, ansi bool) []Section {
+	var sections []Section
 	var buf bytes.Buffer
 
 	write := func(msg string, args ...interface{}) {
 		buf.WriteString(fmt.Sprintf(msg, args...))
 	}
 
+	section := func(name string) {
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		for i, ln := range lines {
+			lines[i] = f.link(ansi, ln)
+		}
+		sections = append(sections, Section{name, lines})
+		buf.Reset()
+	}
+
 	// Title
 	if f.Title != "" {
-		write(sanitize(f.Title) + "\n")
+		write(f.sanitize(f.Title) + "\n")
+		section("Title")
 	}
 
-	pad := func(s string, l int) string {
-		s2 := s
-		for i := 0; i < (l - len(s)); i++ {
-			s2 += " "
-		}
-		return s2
+	// Description
+	if f.Description != "" {
+		write(f.wrapText(f.sanitize(f.Description), f.indent(), lineLen, true))
+		section("Description")
 	}
 
-	wrapText := func(desc string, indentLen int, lineLen int, indentFirstLine bool) {
-		firstLine := true
-		indent := pad("", indentLen)
-		for i, line := range strings.Split(desc, "\n") {
-			firstWord := true
-			writeLn := func(ln string) {
-				firstLine = false
-				firstWord = true
-				write(ln + "\n")
+	// Command usage
+	usageTokens := strings.Split(f.sanitize(f.UsageOptions), "\n")
+	write(f.color(ansi, colorHeader, "Usage:") + strings.TrimPrefix(f.sanitize(f.Synopsis()), "Usage:") + "\n")
+	if l := len(usageTokens); l > 1 {
+		rem := strings.Join(usageTokens[1:l], "\n")
+		write(f.wrapText(rem, f.indent(), lineLen, true))
+	}
+	section("Usage")
+
+	// Commands
+	if len(f.commands) > 0 {
+		write(f.color(ansi, colorHeader, "Commands:") + "\n")
+		// The names aren't formatted, so they're only escaped with the
+		// Sanitizer
+		names := make([]string, len(f.commands))
+		maxNameLen := 0
+		for i, c := range f.commands {
+			names[i] = c.Name
+			if f.Sanitizer != nil {
+				names[i] = f.Sanitizer(names[i])
 			}
-			var ln string
-			if indentFirstLine || i > 0 {
-				ln = indent
+			if l := textWidth(names[i]); l > maxNameLen {
+				maxNameLen = l
+			}
+		}
+		for i, c := range f.commands {
+			s := pad("", f.indent()) + pad(f.color(ansi, colorFlag, names[i]), maxNameLen) + "  "
+			buf.WriteString(s)
+			write(f.wrapText(f.sanitize(c.Summary), textWidth(s), lineLen, false))
+		}
+		section("Commands")
+	}
+
+	// Option/Flag details
+	inGroup := map[string]bool{}
+	for _, g := range f.groups {
+		for _, name := range g.flags {
+			inGroup[name] = true
+		}
+	}
+	notInGroup := func(name string) bool { return !inGroup[name] }
+	ungrouped := f.countShown(notInGroup)
+	if len(f.groups) == 0 || ungrouped > 0 {
+		if f.OptionsHeaderWithCount {
+			write(f.color(ansi, colorHeader, fmt.Sprintf("Options (%d):", ungrouped)) + "\n")
+		} else {
+			write(f.color(ansi, colorHeader, "Options:") + "\n")
+		}
+		buf.WriteString(f.options(f.VisitAll, notInGroup, lineLen, false, ansi))
+		section("Options")
+	}
+	for _, g := range f.groups {
+		// Groups whose flags are all hidden are left out altogether.
+		if f.countShown(g.has) == 0 {
+			continue
+		}
+		write(f.color(ansi, colorHeader, f.sanitize(g.name)+":") + "\n")
+		buf.WriteString(f.options(f.VisitAll, g.has, lineLen, false, ansi))
+		section(g.name)
+	}
+
+	// Examples
+	if examples := f.examples(); len(examples) > 0 {
+		write(f.color(ansi, colorHeader, "Examples:") + "\n")
+		for _, e := range examples {
+			write(pad("", f.indent()) + f.sanitize(f.ExamplePrompt) + f.sanitize(f.withCmdName(e.Command)) + "\n")
+			if e.Desc != "" {
+				write(f.wrapText(f.sanitize(e.Desc), f.indent()+2, lineLen, true))
 			}
-			tokens := strings.Split(line, " ")
-			for _, word := range tokens {
-				length := len(ln)
-				if firstLine && !indentFirstLine {
-					length += len(indent)
-				}
-
-				if length+len(word)+1 > maxLineLength {
-					writeLn(ln)
-					ln = indent
-				}
-				if !firstWord {
-					ln += " "
-				}
-				firstWord = false
-				ln += word
+		}
+		section("Examples")
+	}
+
+	// Exit codes
+	if len(f.ExitCodes) > 0 {
+		write(f.color(ansi, colorHeader, "Exit Status:") + "\n")
+		codes := make([]int, 0, len(f.ExitCodes))
+		maxCodeLen := 0
+		for code := range f.ExitCodes {
+			codes = append(codes, code)
+			if l := len(strconv.Itoa(code)); l > maxCodeLen {
+				maxCodeLen = l
 			}
-			writeLn(ln)
 		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			s := pad("", f.indent()) + pad(strconv.Itoa(code), maxCodeLen) + "  "
+			buf.WriteString(s)
+			write(f.wrapText(f.sanitize(f.ExitCodes[code]), len(s), lineLen, false))
+		}
+		section("Exit Status")
 	}
 
-	// Description
-	if f.Description != "" {
-		wrapText(sanitize(f.Description), 2, maxLineLength, true)
-		write("\n")
+	// Related commands
+	if len(f.Related) > 0 {
+		write(f.color(ansi, colorHeader, "See also:") + "\n")
+		write(f.wrapText(f.sanitize(strings.Join(f.Related, ", ")), f.indent(), lineLen, true))
+		section("See also")
 	}
 
-	// Command usage
-	usageTokens := strings.Split(sanitize(f.UsageOptions), "\n")
-	write("Usage: %s %s\n", f.cmdName, usageTokens[0])
-	if l := len(usageTokens); l > 1 {
-		rem := strings.Join(usageTokens[1:l], "\n")
-		wrapText(rem, 2, maxLineLength, true)
+	return sections
+}
+
+// HasDefault returns true if the flag has a non-Zero default value, i.e.
+// one that is printed in the help screen. It returns false if the flag
+// isn't defined.
+func (f *Flags) HasDefault(name string) bool {
+	fl := f.Lookup(name)
+	return fl != nil && !isZeroValue(fl, f.defValue(fl))
+}
+
+// defValue returns the default value of the flag as it must be rendered.
+func (f *Flags) defValue(fl *flag.Flag) string {
+	if f.DefaultsFromValue {
+		return fl.Value.String()
+	}
+	return fl.DefValue
+}
+
+// displayDefault returns the default value of a flag as it's rendered, or
+// false if it's the zero value.
+func (f *Flags) displayDefault(fl *flag.Flag) (string, bool) {
+	def := f.defValue(fl)
+	if isZeroValue(fl, def) {
+		return "", false
+	}
+	if nf, ok := lookupNumberFormat(f.Locale); ok {
+		return localize(fl, def, nf), true
+	}
+	if f.GroupDigits && isInteger(fl) {
+		return groupDigits(def), true
+	}
+	return def, true
+}
+
+// isInteger returns true if the flag was defined with one of the integer
+// types of the flag package, e.g. with Int.
+func isInteger(fl *flag.Flag) bool {
+	switch flagType(fl) {
+	case "int", "int64", "uint", "uint64":
+		return true
+	}
+	return false
+}
+
+// SetMeta attaches arbitrary metadata to a flag, e.g. for custom
+// documentation generators. niceflags itself ignores it.
+func (f *Flags) SetMeta(flagName, key, value string) {
+	if f.meta == nil {
+		f.meta = map[string]map[string]string{}
+	}
+	if f.meta[flagName] == nil {
+		f.meta[flagName] = map[string]string{}
+	}
+	f.meta[flagName][key] = value
+}
+
+// Group lists the flags under a heading of their own, e.g. "Connection
+// options", in the help screen rather than under "Options". Groups are
+// listed after the ungrouped flags in the order in which they were first
+// declared and calling Group again with the same name adds to the group.
+func (f *Flags) Group(name string, flagNames ...string) {
+	for _, g := range f.groups {
+		if g.name == name {
+			g.flags = append(g.flags, flagNames...)
+			return
+		}
+	}
+	f.groups = append(f.groups, &flagGroup{name, flagNames})
+}
+
+// SetFormatHint documents the format expected for the value of a flag,
+// e.g. "<number>ms", by annotating its description with
+// "(format: <number>ms)". The format isn't validated.
+func (f *Flags) SetFormatHint(flagName, hint string) {
+	if f.formatHints == nil {
+		f.formatHints = map[string]string{}
+	}
+	f.formatHints[flagName] = hint
+}
+
+// SetLongUsage sets a long-form description of a flag, used in place of
+// its usage by the generated documents (man page, Markdown and
+// reStructuredText), while the help screen keeps the usage. Like the usage,
+// it may contain a back-quoted parameter name and `default`.
+func (f *Flags) SetLongUsage(flagName, usage string) {
+	if f.longUsage == nil {
+		f.longUsage = map[string]string{}
+	}
+	f.longUsage[flagName] = usage
+}
+
+// longFlag returns the flag with its long usage, if set with SetLongUsage,
+// in place of its usage.
+func (f *Flags) longFlag(fl *flag.Flag) *flag.Flag {
+	usage, ok := f.longUsage[fl.Name]
+	if !ok {
+		return fl
+	}
+	long := *fl
+	long.Usage = usage
+	return &long
+}
+
+// Meta returns a copy of the metadata attached to a flag with SetMeta.
+func (f *Flags) Meta(flagName string) map[string]string {
+	m := map[string]string{}
+	for k, v := range f.meta[flagName] {
+		m[k] = v
+	}
+	return m
+}
+
+// formatDefault renders a default value with the DefaultFormat.
+func (f *Flags) formatDefault(def string) string {
+	format := f.DefaultFormat
+	if format == "" {
+		format = "(default=%v)"
+	}
+	return fmt.Sprintf(format, def)
+}
+
+// options renders the flags visited by visit, or only those for which
+// include returns true if it's not nil, for the Options section without its
+// heading. The columns are aligned across all the visited flags, so that
+// they line up from one group to another. If values is set, the parameter
+// names are replaced by the current values of the flags. ANSI escape codes
+// are only used if ansi is set.
+func (f *Flags) options(visit func(func(*flag.Flag)), include func(name string) bool, lineLen int, values, ansi bool) string {
+	var buf bytes.Buffer
+	write := func(msg string, args ...interface{}) {
+		buf.WriteString(fmt.Sprintf(msg, args...))
 	}
 
-	// Option/Flag details
-	write("\nOptions:\n")
 	maxFlagLen := 0
 	maxParamLen := 0
 	var flags [][3]string
 
 	computeFormat := func(fl *flag.Flag) {
-		if fl.Name == f.helpFlagName {
-			// skip the help command because it may not be a single character command and
-			// it'll unnecessarily clutter the help screen.
+		if f.skip(fl) {
 			return
 		}
 
-		if l := len(fl.Name); l > maxFlagLen {
+		if l := textWidth(f.dash(fl.Name)); l > maxFlagLen {
 			maxFlagLen = l
 		}
 
-		param := ""
-		usage := sanitize(fl.Usage)
-		if !isZeroValue(fl, fl.DefValue) {
-			if f.PrintAllDefaults {
-				usage = strings.Replace(usage, "`default`", "", -1)
-				usage += fmt.Sprintf("\n[default=%v]", fl.DefValue)
-			} else {
-				usage = strings.Replace(usage, "`default`", fmt.Sprintf("(default=%v)", fl.DefValue), -1)
+		// Only the text given by the user is truncated, so annotations such
+		// as "(required)" are never cut. Phrases that must be kept together
+		// are marked beforehand, so the markers aren't counted.
+		param, text, notes := f.describeParts(fl, true, false)
+		if envVar, ok := f.env[fl.Name]; ok && f.ShowEnvInline {
+			notes += " [env: " + envVar + "]"
+		}
+		usage := f.sanitize(f.truncate(keepTogether(text)) + notes)
+		if values {
+			param = fl.Value.String()
+		}
+		if f.Sanitizer != nil {
+			param = f.Sanitizer(param)
+		}
+		if l := textWidth(param); l > maxParamLen {
+			maxParamLen = l
+		}
+
+		flags = append(flags, [3]string{fl.Name, param, usage})
+	}
+
+	visit(computeFormat)
+	switch f.SortFlags {
+	case SortCaseInsensitive:
+		sort.SliceStable(flags, func(i, j int) bool {
+			return strings.ToLower(flags[i][0]) < strings.ToLower(flags[j][0])
+		})
+	case SortDeclared:
+		rank := map[string]int{}
+		for i, name := range f.order {
+			rank[name] = i + 1
+		}
+		sort.SliceStable(flags, func(i, j int) bool {
+			ri, rj := rank[flags[i][0]], rank[flags[j][0]]
+			return ri != 0 && (rj == 0 || ri < rj)
+		})
+	}
+	if f.RequiredFirst {
+		sort.SliceStable(flags, func(i, j int) bool {
+			return f.isRequired(flags[i][0]) && !f.isRequired(flags[j][0])
+		})
+	}
+	if maxFlagLen < f.MinFlagColumn {
+		maxFlagLen = f.MinFlagColumn
+	}
+
+	sep := f.ColumnSeparator
+	if sep == "" {
+		sep = "  "
+	}
+	stacked := lineLen-(f.indent()+1+maxFlagLen+maxParamLen+textWidth(sep)) < minDescriptionWidth
+	for _, fl := range flags {
+		if include != nil && !include(fl[0]) {
+			continue
+		}
+		if stacked {
+			write("%s%s\n", pad("", f.indent()), strings.TrimSpace(f.color(ansi, colorFlag, f.dash(fl[0]))+" "+f.color(ansi, colorParam, fl[1])))
+			write(f.wrapText(fl[2], f.indent()+4, lineLen, true))
+			continue
+		}
+		s := fmt.Sprintf("%s%s ", pad("", f.indent()), pad(f.color(ansi, colorFlag, f.dash(fl[0])), maxFlagLen))
+		s += pad(f.color(ansi, colorParam, fl[1]), maxParamLen) + sep
+		buf.WriteString(s) // not formatted, so that s is what's printed
+		write(f.wrapText(fl[2], textWidth(s), lineLen, false))
+	}
+	return buf.String()
+}
+
+// describe returns the parameter name and the description of a flag
+// as they must be rendered, i.e. with the back-quoted parameter name
+// extracted, the default value resolved and the {{nowrap}} markers
+// removed.
+func (f *Flags) describe(fl *flag.Flag) (param, usage string) {
+	return f.describeDefault(fl, true, false)
+}
+
+// describeDefault is like describe but leaves the default value out of the
+// description unless withDefault is set, e.g. when it's rendered apart. If
+// quoteParam is set, the parameter name is left back-quoted where it is in
+// the description, e.g. for Markdown.
+func (f *Flags) describeDefault(fl *flag.Flag, withDefault, quoteParam bool) (param, usage string) {
+	param, text, notes := f.describeParts(fl, withDefault, quoteParam)
+	return param, stripNowrap(text) + notes
+}
+
+// describeParts is like describeDefault but returns the description in two
+// parts: the text given by the user, with the back-quoted `default` resolved,
+// and the notes niceflags appends to it, such as the default value when it's
+// rendered apart and annotations like "(required)".
+func (f *Flags) describeParts(fl *flag.Flag, withDefault, quoteParam bool) (param, usage, notes string) {
+	// The parameter name is extracted before the default is resolved so
+	// that the default value can't be mistaken for it.
+	if quoteParam {
+		if i1, i2 := findParam(fl.Usage); i1 != -1 {
+			param = fl.Usage[i1+1 : i2]
+		}
+		usage = fl.Usage
+	} else {
+		param, usage = extractParam(fl.Usage)
+	}
+	def, ok := f.displayDefault(fl)
+	inParam := false
+	if !withDefault {
+		usage = stripDefault(usage)
+	} else if ok {
+		switch {
+		case strings.Contains(def, "\n"):
+			// Multiline defaults are listed line by line below the
+			// description so they stay aligned with it.
+			usage = stripDefault(usage)
+			notes += "\ndefault:"
+			for _, l := range strings.Split(def, "\n") {
+				notes += "\n  " + l
 			}
+		case f.DefaultInParam && param != "":
+			inParam = true
+			usage = stripDefault(usage)
+		case f.PrintAllDefaults && f.InlineDefaults:
+			usage = stripDefault(usage)
+			notes += " " + f.formatDefault(def)
+		case f.PrintAllDefaults:
+			usage = strings.Replace(usage, "`default`", "", -1)
+			notes += "\n" + f.formatDefault(def)
+		default:
+			usage = strings.Replace(usage, "`default`", f.formatDefault(def), -1)
+		}
+	}
 
+	if inParam {
+		param += "=" + def
+	}
+	if f.isRequired(fl.Name) {
+		notes += " (required)"
+	}
+	if others := f.exclusiveWith(fl.Name); len(others) > 0 {
+		notes += " (mutually exclusive with " + strings.Join(others, ", ") + ")"
+	}
+	if _, ok := f.experimental[fl.Name]; ok {
+		notes += " (experimental)"
+	}
+	if _, ok := f.deprecated[fl.Name]; ok {
+		notes += " (deprecated)"
+	}
+	if hint, ok := f.formatHints[fl.Name]; ok {
+		notes += " (format: " + hint + ")"
+	}
+	if typ := flagType(fl); f.ShowTypes && typ != "" {
+		notes += " [" + typ + "]"
+	}
+	return param, usage, notes
+}
+
+// truncate cuts a description down to MaxDescriptionChars characters, if
+// set, and marks it with the TruncationNote.
+func (f *Flags) truncate(usage string) string {
+	if max := f.MaxDescriptionChars; max > 0 && utf8.RuneCountInString(usage) > max {
+		usage = truncateRunes(usage, max)
+		if f.TruncationNote != "" {
+			usage = strings.TrimSuffix(usage, ellipsis) + f.TruncationNote
+		}
+	}
+	return usage
+}
+
+// stripDefault removes the back-quoted `default` from usage, along with
+// the space separating it from the rest of the text.
+func stripDefault(usage string) string {
+	usage = strings.Replace(usage, " `default`", "", -1)
+	usage = strings.Replace(usage, "`default` ", "", -1)
+	return strings.Replace(usage, "`default`", "", -1)
+}
+
+// extractParam returns the parameter name of a flag, i.e. the first
+// back-quoted text in its usage other than `default`, and the usage with
+// the back-quotes around the name removed.
+func extractParam(usage string) (param, rest string) {
+	i1, i2 := findParam(usage)
+	if i1 == -1 {
+		return "", usage
+	}
+	param = usage[i1+1 : i2]
+	return param, usage[:i1] + param + usage[i2+1:]
+}
+
+// findParam returns the positions of the back-quotes around the parameter
+// name in the usage of a flag, or -1 if it has none.
+func findParam(usage string) (i1, i2 int) {
+	for offset := 0; ; {
+		i1 = strings.Index(usage[offset:], "`")
+		if i1 == -1 {
+			return -1, -1
+		}
+		i1 += offset
+		i2 = strings.Index(usage[i1+1:], "`")
+		if i2 == -1 {
+			return -1, -1
+		}
+		i2 += i1 + 1
+		if usage[i1+1:i2] != "default" {
+			return i1, i2
 		}
+		offset = i2 + 1
+	}
+}
 
-		i1 := strings.Index(usage, "`")
-		if i1 != -1 {
-			i2 := strings.Index(usage[i1+1:], "`")
-			if i2 != -1 {
-				param = fl.Usage[i1+1 : i1+i2+1]
-				usage = strings.Replace(usage, "`", "", 2)
+// examples returns the examples to be shown: Examples followed by the
+// detailed examples selected by ShowExampleTags.
+func (f *Flags) examples() []Example {
+	examples := make([]Example, 0, len(f.Examples)+len(f.ExamplesDetailed))
+	for _, e := range f.Examples {
+		examples = append(examples, Example{Command: e})
+	}
+	for _, e := range f.ExamplesDetailed {
+		if f.showExample(e) {
+			examples = append(examples, e)
+		}
+	}
+	return examples
+}
+
+// showExample returns true if the example must be shown as per
+// ShowExampleTags.
+func (f *Flags) showExample(e Example) bool {
+	if len(e.Tags) == 0 || len(f.ShowExampleTags) == 0 {
+		return true
+	}
+	for _, tag := range e.Tags {
+		for _, show := range f.ShowExampleTags {
+			if tag == show {
+				return true
 			}
 		}
-		if l := len(param); l > maxParamLen {
-			maxParamLen = l
+	}
+	return false
+}
+
+// BriefHelp returns a compact, comma-separated list of the flag names
+// (e.g. "-c, -d, -i"), wrapped to the line length. It's meant as a quick
+// reminder of the available flags for CLIs with many options.
+func (f *Flags) BriefHelp() string {
+	var names []string
+	f.VisitAll(func(fl *flag.Flag) {
+		if f.skip(fl) {
+			return
 		}
+		names = append(names, f.dash(fl.Name))
+	})
+	return f.wrapText(strings.Join(names, ", "), 0, f.lineWidth(), false)
+}
 
-		flags = append(flags, [3]string{fl.Name, param, usage})
+// VisibleFlagCount returns the number of flags listed in the help screen.
+func (f *Flags) VisibleFlagCount() int {
+	return f.countShown(func(string) bool { return true })
+}
+
+// dash returns the flag name prefixed with the dash(es) it's rendered
+// with.
+func (f *Flags) dash(name string) string {
+	if f.DoubleDash && utf8.RuneCountInString(name) > 1 {
+		return "--" + name
 	}
+	return "-" + name
+}
 
-	f.VisitAll(computeFormat)
+// indent returns the number of spaces the sections are indented by.
+func (f *Flags) indent() int {
+	if f.Indent > 0 {
+		return f.Indent
+	}
+	return 2
+}
 
-	for _, fl := range flags {
-		s := fmt.Sprintf("  -%s ", pad(fl[0], maxFlagLen))
-		s += fmt.Sprintf("%s  ", pad(fl[1], maxParamLen))
-		write(s)
-		wrapText(fl[2], len(s), maxLineLength, false)
+// countShown returns the number of flags shown on the help screen among
+// those whose names are accepted by include.
+func (f *Flags) countShown(include func(name string) bool) int {
+	n := 0
+	f.VisitAll(func(fl *flag.Flag) {
+		if !f.skip(fl) && include(fl.Name) {
+			n++
+		}
+	})
+	return n
+}
+
+// skip returns true if the flag must be left out of the help screen.
+func (f *Flags) skip(fl *flag.Flag) bool {
+	// skip the help command because it may not be a single character command and
+	// it'll unnecessarily clutter the help screen.
+	return fl.Name == f.helpFlagName || (f.versionFlagName != "" && fl.Name == f.versionFlagName) || f.hidden[fl.Name]
+}
+
+// Hide leaves the flags out of the help screen, e.g. for internal or
+// debugging flags. They can still be given on the command line.
+func (f *Flags) Hide(flagNames ...string) {
+	if f.hidden == nil {
+		f.hidden = map[string]bool{}
+	}
+	for _, name := range flagNames {
+		f.hidden[name] = true
 	}
+}
 
-	// Examples
-	if f.Examples != nil && len(f.Examples) > 0 {
-		write("\nExamples:\n")
-		for _, e := range f.Examples {
-			write("  %s %s\n", f.cmdName, sanitize(e))
+// wrapText wraps desc to lineLen characters. Every line but the first is
+// indented by indentLen spaces. The first line is indented only if
+// indentFirstLine is set; otherwise it's assumed to continue a line that
+// is already indentLen characters long.
+func (f *Flags) wrapText(desc string, indentLen int, lineLen int, indentFirstLine bool) string {
+	var buf bytes.Buffer
+	firstLine := true
+	indent := pad("", indentLen)
+	if f.Wrapper != nil {
+		desc = strings.Replace(keepTogether(desc), nbsp, " ", -1)
+		for i, ln := range f.Wrapper(desc, lineLen-indentLen) {
+			if indentFirstLine || i > 0 {
+				ln = indent + ln
+			}
+			buf.WriteString(ln + "\n")
 		}
+		return buf.String()
 	}
+	for i, line := range strings.Split(keepTogether(desc), "\n") {
+		firstWord := true
+		writeLn := func(ln string) {
+			firstLine = false
+			firstWord = true
+			buf.WriteString(strings.Replace(ln, nbsp, " ", -1) + "\n")
+		}
+		var ln string
+		if indentFirstLine || i > 0 {
+			ln = indent
+		}
+		wrapIndent := indent
+		if f.BulletMarker != "" {
+			// Wrapped list items are indented under the item's text
+			if lead, item, ok := listItem(line); ok {
+				line = lead + f.BulletMarker + " " + item
+				wrapIndent += pad("", textWidth(lead)+textWidth(f.BulletMarker)+1)
+			}
+		}
+		tokens := strings.Split(line, " ")
+		urlBreak := false
+		for _, word := range tokens {
+			length := textWidth(ln)
+			if firstLine && !indentFirstLine {
+				length += textWidth(indent)
+			}
 
+			// A line is never broken before its first word, so descriptions
+			// always start on the flag's line. Lines holding nothing but
+			// indentation are only kept with KeepURLs, so URLs longer than
+			// the line don't leave blank lines behind.
+			indentOnly := firstWord && (indentFirstLine || !firstLine)
+			if !firstWord {
+				length++ // separating space
+			}
+			fits := length+textWidth(word) <= lineLen
+			if !fits && (!firstWord || indentOnly && !f.KeepURLs) {
+				writeLn(ln)
+				ln = wrapIndent
+			}
+			if !firstWord {
+				ln += " "
+			}
+			firstWord = false
+			ln += word
+			urlBreak = false
+			if f.KeepURLs && !fits && isURL(word) {
+				writeLn(ln)
+				ln = wrapIndent
+				urlBreak = true
+			}
+		}
+		if !urlBreak {
+			writeLn(ln)
+		}
+	}
+	return buf.String()
+}
+
+const (
+	nowrapStart = "{{nowrap}}"
+	nowrapEnd   = "{{/nowrap}}"
+
+	// nbsp stands in for the spaces of a phrase that must not be broken
+	// while wrapping. It has the same length as a space.
+	nbsp = "\x00"
+)
+
+// keepTogether removes the {{nowrap}}...{{/nowrap}} markers from s and
+// replaces the spaces between them with nbsp, so the marked phrase is
+// wrapped as a single word.
+func keepTogether(s string) string {
+	var buf bytes.Buffer
+	for {
+		i := strings.Index(s, nowrapStart)
+		if i == -1 {
+			break
+		}
+		j := strings.Index(s[i:], nowrapEnd)
+		if j == -1 {
+			break
+		}
+		buf.WriteString(s[:i])
+		buf.WriteString(strings.Replace(s[i+len(nowrapStart):i+j], " ", nbsp, -1))
+		s = s[i+j+len(nowrapEnd):]
+	}
+	buf.WriteString(s)
 	return buf.String()
 }
 
+// stripNowrap removes the {{nowrap}}...{{/nowrap}} markers from s, for
+// output that isn't wrapped by niceflags.
+func stripNowrap(s string) string {
+	return nowrapStripper.Replace(s)
+}
+
+var nowrapStripper = strings.NewReplacer(nowrapStart, "", nowrapEnd, "")
+
+// groupDigits separates the thousands of an integer with commas, e.g.
+// "10000000" becomes "10,000,000". Anything else is returned as is.
+func groupDigits(s string) string {
+	return groupThousands(s, ",")
+}
+
+// groupThousands separates the thousands of an integer with sep.
+// Anything else is returned as is.
+func groupThousands(s, sep string) string {
+	if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+		if _, err := strconv.ParseUint(s, 10, 64); err != nil {
+			return s
+		}
+	}
+	sign := ""
+	if s[0] == '-' || s[0] == '+' {
+		sign, s = s[:1], s[1:]
+	}
+	var buf bytes.Buffer
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			buf.WriteString(sep)
+		}
+		buf.WriteRune(c)
+	}
+	return sign + buf.String()
+}
+
+// listMarkers are the bullets recognized as starting list items.
+var listMarkers = []string{"- ", "* ", "• "}
+
+// listItem splits a line starting with a list marker (possibly indented)
+// into its indentation and the text of the item.
+func listItem(line string) (lead, item string, ok bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	for _, m := range listMarkers {
+		if strings.HasPrefix(trimmed, m) {
+			return line[:len(line)-len(trimmed)], strings.TrimLeft(trimmed[len(m):], " "), true
+		}
+	}
+	return "", "", false
+}
+
+// isURL returns true if the word is a web URL.
+func isURL(word string) bool {
+	return strings.HasPrefix(word, "http://") || strings.HasPrefix(word, "https://")
+}
+
+// ellipsis marks truncated text.
+const ellipsis = "..."
+
+// truncateRunes cuts s down to max characters and marks it with an
+// ellipsis. It never splits a multibyte character. s is returned as is
+// if it's short enough.
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimRight(string(runes[:max]), " ") + ellipsis
+}
+
+func pad(s string, l int) string {
+	s2 := s
+	for i := 0; i < (l - textWidth(s)); i++ {
+		s2 += " "
+	}
+	return s2
+}
+
 // isZeroValue guesses whether the string represents the zero
 // value for a flag. It is not accurate but in practice works OK.
 // This is a direct copy from the flag package
@@ -277,8 +1425,23 @@ func isZeroValue(fl *flag.Flag, value string) bool {
 	return false
 }
 
+// sanitize escapes user given text with the Sanitizer, if set, and then
+// escapes % signs for the formatting of the help text.
+func (f *Flags) sanitize(msg string) string {
+	if f.Sanitizer != nil {
+		msg = f.Sanitizer(msg)
+	}
+	return sanitize(msg)
+}
+
 func sanitize(msg string) string {
 	msg = strings.Replace(msg, "%", "%%", -1)
+	return unescapeNewlines(msg)
+}
+
+// unescapeNewlines converts literal "\\n" sequences in user given text
+// to new lines.
+func unescapeNewlines(msg string) string {
 	return strings.Replace(msg, "\\n", "\n", -1)
 }
 
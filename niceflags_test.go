@@ -6,12 +6,25 @@
 package niceflags
 
 import (
+	"bytes"
 	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
 	"math"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
 
+func TestMain(m *testing.M) {
+	// The expected help screens are 72 columns wide regardless of the
+	// terminal the tests are run in.
+	terminalWidth = func() int { return maxLineLength }
+	os.Exit(m.Run())
+}
+
 func TestFlags(t *testing.T) {
 	// Create flag set
 	flags := NewFlags(
@@ -117,6 +130,1274 @@ func TestHelpChecker(t *testing.T) {
 	compare(t, true, flags.AskingHelp())
 }
 
+func TestHelpFlagName(t *testing.T) {
+	flags := NewFlags("", "", "", "", "helpme", false)
+	compare(t, "helpme", flags.HelpFlagName())
+}
+
+func TestBriefHelp(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	names := []string{"c", "d", "extra", "i", "p", "s", "t", "w"}
+	for _, n := range names {
+		flags.Bool(n, false, "")
+	}
+
+	got := flags.BriefHelp()
+	compare(t, "-c, -d, -extra, -i, -p, -s, -t, -w\n", got)
+	for _, n := range names {
+		if !strings.Contains(got, "-"+n) {
+			t.Errorf("brief help doesn't list -%s: %q", n, got)
+		}
+	}
+	if strings.Contains(got, "-help") {
+		t.Errorf("brief help lists the help flag: %q", got)
+	}
+}
+
+func TestTruncation(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.MaxDescriptionChars = 20
+	flags.TruncationNote = "... (see man page)"
+	flags.Bool("w", false, "Wait for a response from the server.")
+	flags.Bool("x", false, "Short one.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -w   Wait for a response... (see man page)\n" +
+		"  -x   Short one.\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestTruncationKeepsNotes(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.MaxDescriptionChars = 20
+	flags.TruncationNote = "... (see man page)"
+	usage := "Wait for a response from the server, which must answer in time."
+	flags.Bool("w", false, usage)
+	flags.Require("w")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -w   Wait for a response... (see man page) (required)\n"
+	compare(t, exp, flags.HelpText())
+
+	// Generated documents aren't truncated
+	var man bytes.Buffer
+	if err := flags.GenManPage(&man, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(man.String(), "\n"+usage+" (required)\n") {
+		t.Errorf("man page is truncated:\n%s", man.String())
+	}
+	if md := flags.HelpMarkdown(); !strings.Contains(md, "| "+usage+" (required) |") {
+		t.Errorf("Markdown is truncated:\n%s", md)
+	}
+	if rst := flags.RST(); !strings.Contains(rst, "   "+usage+" (required)\n") {
+		t.Errorf("reStructuredText is truncated:\n%s", rst)
+	}
+}
+
+func TestNowrapInDocuments(t *testing.T) {
+	flags := NewFlags("pping", "", "Ping {{nowrap}}a host{{/nowrap}}.", "", "help", false)
+	flags.Bool("6", false, "Use {{nowrap}}IPv6 only{{/nowrap}}.")
+	flags.ExitCodes = map[int]string{1: "{{nowrap}}No reply{{/nowrap}}."}
+
+	var man bytes.Buffer
+	if err := flags.GenManPage(&man, 1); err != nil {
+		t.Fatal(err)
+	}
+	docs := map[string]string{
+		"man page":         man.String(),
+		"Markdown":         flags.HelpMarkdown(),
+		"reStructuredText": flags.RST(),
+		"PowerShell":       flags.PowerShellCompletion(),
+	}
+	for name, doc := range docs {
+		if strings.Contains(doc, "nowrap") || strings.Contains(doc, nbsp) {
+			t.Errorf("%s contains nowrap markers:\n%s", name, doc)
+		}
+		if !strings.Contains(doc, "Use IPv6 only.") {
+			t.Errorf("%s is missing the usage:\n%s", name, doc)
+		}
+	}
+	if !strings.Contains(man.String(), "\nPing a host.\n") || !strings.Contains(man.String(), "\nNo reply.\n") {
+		t.Errorf("man page is missing the description or exit codes:\n%s", man.String())
+	}
+}
+
+func TestHelpRequestedIn(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Int("c", 0, "")
+
+	compare(t, true, flags.HelpRequestedIn([]string{"-c", "notanumber", "-bogus", "-help"}))
+	compare(t, true, flags.HelpRequestedIn([]string{"--help"}))
+	compare(t, true, flags.HelpRequestedIn([]string{"-help=true"}))
+	compare(t, false, flags.HelpRequestedIn([]string{"-help=false"}))
+	compare(t, false, flags.HelpRequestedIn([]string{"-c", "5", "-helpme"}))
+	compare(t, false, flags.HelpRequestedIn([]string{"-c", "5", "--", "-help"}))
+}
+
+func TestWriteHelpFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "niceflags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	flags := NewFlags("pping", "pping - Protocol Ping", "", "[options] host port", "help", false)
+	flags.Int("s", 64, "Payload `size` in bytes `default`.")
+
+	path := filepath.Join(dir, "docs", "pping.txt")
+	if err := flags.WriteHelpFile(path); err != nil {
+		t.Fatal("error when writing help file", err)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, flags.HelpText(), string(b))
+
+	// Only the help file must be left behind
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, 1, len(files))
+
+	// Errors from the file system are propagated
+	if err := flags.WriteHelpFile(filepath.Join(path, "nested.txt")); err == nil {
+		t.Error("expected an error when the parent is a file")
+	}
+}
+
+// unitValue is a flag.Value whose unit is configured after the flag is
+// defined, so its String differs from the default captured by the flag.
+type unitValue struct {
+	n    int
+	unit string
+}
+
+func (u *unitValue) String() string {
+	if u == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d%s", u.n, u.unit)
+}
+
+func (u *unitValue) Set(s string) error {
+	_, err := fmt.Sscanf(s, "%d", &u.n)
+	return err
+}
+
+func TestDefaultsFromValue(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	timeout := &unitValue{n: 500}
+	flags.Var(timeout, "t", "Max `time`-to-live `default`.")
+	timeout.unit = "ms"
+
+	exp := func(def string) string {
+		return "Usage: pping \n" +
+			"\n" +
+			"Options:\n" +
+			"  -t time  Max time-to-live (default=" + def + ").\n"
+	}
+	compare(t, exp("500"), flags.HelpText())
+	flags.DefaultsFromValue = true
+	compare(t, exp("500ms"), flags.HelpText())
+}
+
+func TestRelated(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Related = []string{
+		"pping-scan", "pping-trace", "pping-stats", "pping-report",
+		"pping-monitor", "pping-export", "pping-import",
+	}
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"\n" +
+		"See also:\n" +
+		"  pping-scan, pping-trace, pping-stats, pping-report, pping-monitor,\n" +
+		"  pping-export, pping-import\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestDefaultInParam(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.DefaultInParam = true
+	flags.Int("s", 64, "Payload `size` in bytes `default`.")
+	flags.Bool("v", true, "Verbose output `default`.")
+	flags.String("d", "", "DNS `server` to use.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -d server   DNS server to use.\n" +
+		"  -s size=64  Payload size in bytes.\n" +
+		"  -v          Verbose output (default=true).\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestTruncateRunes(t *testing.T) {
+	compare(t, "short", truncateRunes("short", 5))
+	compare(t, "プロトコル...", truncateRunes("プロトコルを指定します", 5))
+	compare(t, "Wait for...", truncateRunes("Wait for a response", 9))
+
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.MaxDescriptionChars = 5
+	flags.Bool("p", false, "プロトコルを指定します")
+	compare(t, "Usage: pping \n\nOptions:\n  -p   プロトコル...\n", flags.HelpText())
+}
+
+func TestShowTypes(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.ShowTypes = true
+	flags.Int("c", 0, "Stop after sending specified `num`ber of pings.")
+	flags.Duration("t", 0, "Max `time`-to-live.")
+	flags.Var(&unitValue{}, "u", "Custom value.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -c num   Stop after sending specified number of pings. [int]\n" +
+		"  -t time  Max time-to-live. [duration]\n" +
+		"  -u       Custom value.\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestKeepURLs(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.KeepURLs = true
+	url := "https://example.com/docs/pping/protocols/reference/index.html"
+	flags.String("p", "", "Specify `protocol` to use. See "+url+" for details.")
+	flags.Bool("x", false, "Docs: "+url+"#a-very-long-anchor-that-never-fits")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -p protocol  Specify protocol to use. See\n" +
+		"               " + url + "\n" +
+		"               for details.\n" +
+		"  -x           Docs:\n" +
+		"               " + url + "#a-very-long-anchor-that-never-fits\n"
+	compare(t, exp, flags.HelpText())
+
+	// Without KeepURLs, lines are broken before words that don't fit, as
+	// usual, even if they hold nothing but indentation
+	flags = NewFlags("pping", "", url+"#a-very-long-anchor-that-never-fits", "", "help", false)
+	description := func() string { return strings.Join(flags.HelpSections()[0].Lines, "\n") }
+	compare(t, "  \n  "+url+"#a-very-long-anchor-that-never-fits", description())
+	flags.KeepURLs = true
+	compare(t, "  "+url+"#a-very-long-anchor-that-never-fits", description())
+}
+
+func TestExampleTags(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Examples = []string{"google.com 80"}
+	flags.ExamplesDetailed = []Example{
+		{Command: "-s 128 google.com 80", Tags: []string{"basic"}},
+		{Command: "-p udp -c 5 -t 1000 myserver.com 8085", Tags: []string{"advanced"}},
+		{Command: "-w myserver.com 8085"},
+	}
+
+	exp := func(examples ...string) string {
+		s := "Usage: pping \n" +
+			"\n" +
+			"Options:\n" +
+			"\n" +
+			"Examples:\n"
+		for _, e := range examples {
+			s += "  pping " + e + "\n"
+		}
+		return s
+	}
+
+	compare(t, exp("google.com 80", "-s 128 google.com 80", "-p udp -c 5 -t 1000 myserver.com 8085",
+		"-w myserver.com 8085"), flags.HelpText())
+
+	flags.ShowExampleTags = []string{"basic"}
+	compare(t, exp("google.com 80", "-s 128 google.com 80", "-w myserver.com 8085"), flags.HelpText())
+
+	flags.ShowExampleTags = []string{"expert"}
+	compare(t, exp("google.com 80", "-w myserver.com 8085"), flags.HelpText())
+}
+
+func TestPostProcess(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Bool("w", false, "Wait for a response.")
+	flags.PostProcess = func(s string) string {
+		for _, h := range []string{"Usage:", "Options:"} {
+			s = strings.Replace(s, h, strings.ToUpper(h), -1)
+		}
+		return s
+	}
+
+	exp := "USAGE: pping \n" +
+		"\n" +
+		"OPTIONS:\n" +
+		"  -w   Wait for a response.\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestDoubleDash(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.DoubleDash = true
+	flags.String("protocol", "", "Specify `protocol` to use.")
+	flags.Bool("w", false, "Wait for a response.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  --protocol protocol  Specify protocol to use.\n" +
+		"  -w                   Wait for a response.\n"
+	compare(t, exp, flags.HelpText())
+	compare(t, "--protocol, -w\n", flags.BriefHelp())
+
+	if err := flags.Parse([]string{"--protocol", "udp", "-w"}); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, "udp", flags.Lookup("protocol").Value.String())
+}
+
+func TestExamplePrompt(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.ExamplePrompt = "$ "
+	flags.Examples = []string{"-s 128 google.com 80"}
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"\n" +
+		"Examples:\n" +
+		"  $ pping -s 128 google.com 80\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestWrapper(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	var widths []int
+	flags.Wrapper = func(text string, width int) []string {
+		widths = append(widths, width)
+		return strings.Split(text, " ")
+	}
+	flags.String("p", "", "Specify `protocol` to use.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -p protocol  Specify\n" +
+		"               protocol\n" +
+		"               to\n" +
+		"               use.\n"
+	compare(t, exp, flags.HelpText())
+	compare(t, 1, len(widths))
+	compare(t, 72-15, widths[0])
+}
+
+func TestMultilineDefault(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.String("f", "{{.Host}}:{{.Port}}\n{{.Latency}} ms", "Output `format` `default`.")
+	flags.Int("s", 64, "Payload `size` in bytes `default`.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -f format  Output format.\n" +
+		"             default:\n" +
+		"               {{.Host}}:{{.Port}}\n" +
+		"               {{.Latency}} ms\n" +
+		"  -s size    Payload size in bytes (default=64).\n"
+	compare(t, exp, flags.HelpText())
+
+	flags.PrintAllDefaults = true
+	if got := flags.HelpText(); !strings.Contains(got, "default:\n               {{.Host}}:{{.Port}}\n") {
+		t.Errorf("multiline default isn't aligned with PrintAllDefaults:\n%s", got)
+	}
+}
+
+func TestInlineDefaults(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", true)
+	flags.InlineDefaults = true
+	flags.Int("s", 64, "Payload `size` in bytes `default`.")
+	flags.Int("i", 1000, "Interval `time` between pings in ms.")
+	flags.Int("c", 0, "Stop after sending specified `num`ber of pings.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -c num   Stop after sending specified number of pings.\n" +
+		"  -i time  Interval time between pings in ms. (default=1000)\n" +
+		"  -s size  Payload size in bytes. (default=64)\n"
+	compare(t, exp, flags.HelpText())
+
+	flags.InlineDefaults = false
+	if got := flags.HelpText(); !strings.Contains(got, "in ms.\n           (default=1000)\n") {
+		t.Errorf("defaults aren't on their own line:\n%s", got)
+	}
+}
+
+func TestVisibleFlagCount(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	compare(t, 0, flags.VisibleFlagCount())
+	flags.Int("c", 0, "")
+	flags.Bool("w", false, "")
+	compare(t, 2, flags.VisibleFlagCount())
+
+	// Hidden flags and the version flag aren't counted
+	flags.Bool("debug", false, "")
+	flags.Hide("debug", "w")
+	flags.EnableVersion("version", "v1.2")
+	compare(t, 1, flags.VisibleFlagCount())
+}
+
+func TestColumnSeparator(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.ColumnSeparator = " : "
+	flags.Int("c", 0, "Stop after sending specified `num`ber of pings.")
+	flags.String("d", "", "DNS `server` IP address to use. This can be specified for name "+
+		"resolution on systems that don't use the traditional DNS server configurations.")
+	flags.Bool("w", false, "Wait for a response.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -c num    : Stop after sending specified number of pings.\n" +
+		"  -d server : DNS server IP address to use. This can be specified for\n" +
+		"              name resolution on systems that don't use the traditional\n" +
+		"              DNS server configurations.\n" +
+		"  -w        : Wait for a response.\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestHelpTextWidth(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.String("d", "", "DNS `server` IP address to use, e.g. /etc/resolv.conf.")
+
+	text, overflow := flags.HelpTextWidth(40)
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -d server  DNS server IP address to\n" +
+		"             use, e.g. /etc/resolv.conf.\n"
+	compare(t, exp, text)
+	compare(t, false, overflow)
+
+	_, overflow = flags.HelpTextWidth(25)
+	compare(t, true, overflow)
+}
+
+func TestExitCodes(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.ExitCodes = map[int]string{
+		0:  "All pings were answered.",
+		1:  "Some pings timed out. This also happens when the server drops packets because of rate limiting.",
+		64: "Usage error.",
+	}
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"\n" +
+		"Exit Status:\n" +
+		"  0   All pings were answered.\n" +
+		"  1   Some pings timed out. This also happens when the server drops\n" +
+		"      packets because of rate limiting.\n" +
+		"  64  Usage error.\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestDescriptionStartsOnFlagLine(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.String("d", "", "DNS `server` address.")
+
+	// "  -d server  DNS server" is exactly 23 characters wide
+	text, overflow := flags.HelpTextWidth(23)
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -d server  DNS server\n" +
+		"             address.\n"
+	compare(t, exp, text)
+	compare(t, false, overflow)
+
+	// The first word stays on the flag line even if it doesn't fit
+	flags = NewFlags("pping", "", "", "", "help", false)
+	flags.String("d", "", "/etc/resolv.conf `server` address.")
+	text, overflow = flags.HelpTextWidth(23)
+	exp = "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -d server  /etc/resolv.conf\n" +
+		"             server\n" +
+		"             address.\n"
+	compare(t, exp, text)
+	compare(t, true, overflow)
+}
+
+func TestGroupDigits(t *testing.T) {
+	compare(t, "10,000,000", groupDigits("10000000"))
+	compare(t, "-1,000", groupDigits("-1000"))
+	compare(t, "999", groupDigits("999"))
+	compare(t, "18,446,744,073,709,551,615", groupDigits("18446744073709551615"))
+	compare(t, "1500.5", groupDigits("1500.5"))
+
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.GroupDigits = true
+	flags.Int("t", 10000000, "Max `time`-to-live in ms `default`.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -t time  Max time-to-live in ms (default=10,000,000).\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestGroupDigitsIntegersOnly(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.GroupDigits = true
+	flags.String("zip", "94107", "Server location `code` `default`.")
+	flags.Float64("r", 2500.5, "Max `rate` `default`.")
+	flags.Uint64("s", 65536, "Packet `size` `default`.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -r   rate  Max rate (default=2500.5).\n" +
+		"  -s   size  Packet size (default=65,536).\n" +
+		"  -zip code  Server location code (default=94107).\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestHasDefault(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Int("s", 64, "")
+	flags.Int("c", 0, "")
+	flags.String("p", "tcp", "")
+	flags.String("d", "", "")
+	flags.Bool("w", false, "")
+	flags.Bool("v", true, "")
+
+	for name, exp := range map[string]bool{
+		"s": true, "c": false, "p": true, "d": false, "w": false, "v": true,
+		"help": false, "undefined": false,
+	} {
+		if got := flags.HasDefault(name); got != exp {
+			t.Errorf("HasDefault(%q): expected: %v, got: %v", name, exp, got)
+		}
+	}
+}
+
+func TestBulletMarker(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.BulletMarker = "*"
+	flags.String("p", "", "Specify `protocol` to use. Valid values are:\n"+
+		"- tcp: also supports 4 or 6 only counterparts, i.e. tcp4 and tcp6.\n"+
+		"* udp: also supports 4 or 6 only counterparts.\n"+
+		"•   icmp")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -p protocol  Specify protocol to use. Valid values are:\n" +
+		"               * tcp: also supports 4 or 6 only counterparts, i.e. tcp4\n" +
+		"                 and tcp6.\n" +
+		"               * udp: also supports 4 or 6 only counterparts.\n" +
+		"               * icmp\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestMeta(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Int("c", 0, "Stop after sending specified `num`ber of pings.")
+	help := flags.HelpText()
+
+	flags.SetMeta("c", "stability", "stable")
+	flags.SetMeta("c", "link", "https://example.com/pping#c")
+	m := flags.Meta("c")
+	compare(t, 2, len(m))
+	compare(t, "stable", m["stability"])
+	compare(t, "https://example.com/pping#c", m["link"])
+	compare(t, 0, len(flags.Meta("w")))
+
+	// The returned map is a copy
+	m["stability"] = "experimental"
+	compare(t, "stable", flags.Meta("c")["stability"])
+
+	compare(t, help, flags.HelpText())
+}
+
+func TestOmitCommandName(t *testing.T) {
+	flags := NewFlags("pping", "", "", "[options] host port", "help", false)
+	flags.OmitCommandName = true
+	flags.ExamplePrompt = "$ "
+	flags.Examples = []string{"-s 128 google.com 80"}
+
+	exp := "Usage: [options] host port\n" +
+		"\n" +
+		"Options:\n" +
+		"\n" +
+		"Examples:\n" +
+		"  $ -s 128 google.com 80\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestHelpSections(t *testing.T) {
+	flags := NewFlags("pping", "pping - Protocol Ping", "Tool to simulate TCP and UDP pings.",
+		"[options] host port", "help", false)
+	flags.Examples = []string{"-s 128 google.com 80"}
+	flags.ExitCodes = map[int]string{0: "Success."}
+	flags.Related = []string{"pping-scan"}
+	flags.Int("s", 64, "Payload `size` in bytes `default`.")
+
+	sections := flags.HelpSections()
+	exp := []Section{
+		{"Title", []string{"pping - Protocol Ping"}},
+		{"Description", []string{"  Tool to simulate TCP and UDP pings."}},
+		{"Usage", []string{"Usage: pping [options] host port"}},
+		{"Options", []string{"Options:", "  -s size  Payload size in bytes (default=64)."}},
+		{"Examples", []string{"Examples:", "  pping -s 128 google.com 80"}},
+		{"Exit Status", []string{"Exit Status:", "  0  Success."}},
+		{"See also", []string{"See also:", "  pping-scan"}},
+	}
+	compare(t, fmt.Sprint(exp), fmt.Sprint(sections))
+
+	var text []string
+	for i, sec := range sections {
+		if i > 1 {
+			text = append(text, "")
+		}
+		text = append(text, sec.Lines...)
+	}
+	compare(t, flags.HelpText(), strings.Join(text, "\n")+"\n")
+}
+
+func TestHelpSectionsTerminal(t *testing.T) {
+	// The sections make up the help file even if the output is a terminal
+	defer func(fn func(io.Writer) bool) { isTerminal = fn }(isTerminal)
+	isTerminal = func(w io.Writer) bool { return true }
+	defer os.Setenv("NO_COLOR", os.Getenv("NO_COLOR"))
+	os.Unsetenv("NO_COLOR")
+
+	flags := NewFlags("pping", "pping - Protocol Ping", "", "[options] host", "help", false)
+	flags.Color = true
+	flags.Int("s", 64, "Payload `size` in bytes `default`.")
+
+	dir, err := ioutil.TempDir("", "niceflags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "pping.txt")
+	if err := flags.WriteHelpFile(path); err != nil {
+		t.Fatal(err)
+	}
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var text []string
+	for i, sec := range flags.HelpSections() {
+		if i > 1 {
+			text = append(text, "")
+		}
+		text = append(text, sec.Lines...)
+	}
+	compare(t, string(file), strings.Join(text, "\n")+"\n")
+	if strings.Contains(string(file), "\x1b") {
+		t.Errorf("colors used in the sections:\n%q", file)
+	}
+}
+
+func TestOptionsHeaderWithCount(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.OptionsHeaderWithCount = true
+	flags.Int("c", 0, "Count.")
+	flags.Bool("w", false, "Wait.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options (2):\n" +
+		"  -c   Count.\n" +
+		"  -w   Wait.\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestDefaultFormat(t *testing.T) {
+	for _, c := range []struct {
+		printAll       bool
+		inline, custom string
+	}{
+		{false,
+			"  -i time  Interval time between pings in ms (default=1000).\n",
+			"  -i time  Interval time between pings in ms <1000>.\n"},
+		{true,
+			"  -i time  Interval time between pings in ms. (default=1000)\n",
+			"  -i time  Interval time between pings in ms .\n" +
+				"           <1000>\n"},
+	} {
+		flags := NewFlags("pping", "", "", "", "help", c.printAll)
+		flags.InlineDefaults = true
+		flags.Int("i", 1000, "Interval `time` between pings in ms `default`.")
+		compare(t, "Usage: pping \n\nOptions:\n"+c.inline, flags.HelpText())
+
+		flags.InlineDefaults = false
+		flags.DefaultFormat = "<%v>"
+		compare(t, "Usage: pping \n\nOptions:\n"+c.custom, flags.HelpText())
+	}
+}
+
+func TestNoWrapMarker(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Bool("w", false, "Wait for a response from the server before sending next "+
+		"{{nowrap}}Protocol Ping{{/nowrap}} request.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -w   Wait for a response from the server before sending next\n" +
+		"       Protocol Ping request.\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestNarrowHelp(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Int("s", 64, "Payload `size` in bytes `default`.")
+	flags.Bool("w", false, "Wait for a response.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -s size\n" +
+		"      Payload size\n" +
+		"      in bytes\n" +
+		"      (default=64).\n" +
+		"  -w\n" +
+		"      Wait for a\n" +
+		"      response.\n"
+	got, _ := flags.HelpTextWidth(20)
+	compare(t, exp, got)
+}
+
+func TestCmdNameFunc(t *testing.T) {
+	flags := NewFlags("/usr/bin/multitool", "", "", "[options] host", "help", false)
+	flags.Examples = []string{"google.com"}
+	flags.CmdNameFunc = func() string { return "pping" }
+
+	exp := "Usage: pping [options] host\n" +
+		"\n" +
+		"Options:\n" +
+		"\n" +
+		"Examples:\n" +
+		"  pping google.com\n"
+	compare(t, exp, flags.HelpText())
+	compare(t, "Usage: pping [options] host", flags.Synopsis())
+}
+
+func TestFormatHint(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.String("t", "", "Ping `timeout`.")
+	flags.Bool("w", false, "Wait for a response.")
+	flags.SetFormatHint("t", "<number>ms")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -t timeout  Ping timeout. (format: <number>ms)\n" +
+		"  -w          Wait for a response.\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestMinFlagColumn(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.MinFlagColumn = 10
+	flags.Int("c", 0, "Stop after sending specified `num`ber of pings.")
+	flags.Bool("w", false, "Wait for a response.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -c         num  Stop after sending specified number of pings.\n" +
+		"  -w              Wait for a response.\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestHelpTextSetOnly(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Int("c", 0, "Stop after sending specified `num`ber of pings.")
+	flags.Int("s", 64, "Payload `size` in bytes `default`.")
+	flags.String("p", "tcp", "Specify `protocol` to use.")
+	flags.Bool("w", false, "Wait for a response.")
+	if err := flags.Parse([]string{"-s", "1024", "-w", "google.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := "Options:\n" +
+		"  -s 1024  Payload size in bytes (default=64).\n" +
+		"  -w true  Wait for a response.\n"
+	compare(t, exp, flags.HelpTextSetOnly())
+}
+
+func TestHelpTextSetOnlyImplied(t *testing.T) {
+	defer os.Setenv("PPING_PROTOCOL", os.Getenv("PPING_PROTOCOL"))
+	os.Setenv("PPING_PROTOCOL", "udp")
+
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Int("s", 64, "Payload `size` in bytes.")
+	flags.String("p", "tcp", "Specify `protocol` to use.")
+	flags.Bool("w", false, "Wait for a response.")
+	flags.Preset("fast", "Fastest settings.", map[string]string{"s": "16"})
+	flags.BindEnv("p", "PPING_PROTOCOL")
+	if err := flags.Parse([]string{"-fast", "-w"}); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := "Options:\n" +
+		"  -fast true  Fastest settings.\n" +
+		"  -w    true  Wait for a response.\n"
+	compare(t, exp, flags.HelpTextSetOnly())
+}
+
+func TestSanitizer(t *testing.T) {
+	flags := NewFlags("pping", "pping <Protocol Ping>", "", "[options] <host>", "help", false)
+	flags.Sanitizer = html.EscapeString
+	flags.Examples = []string{"-s 10% google.com"}
+	flags.Int("s", 64, "Payload `<size>` in bytes, < 64K & > 0 `default`.")
+
+	exp := "pping &lt;Protocol Ping&gt;\n" +
+		"Usage: pping [options] &lt;host&gt;\n" +
+		"\n" +
+		"Options:\n" +
+		"  -s &lt;size&gt;  Payload &lt;size&gt; in bytes, &lt; 64K &amp; &gt; 0\n" +
+		"                   (default=64).\n" +
+		"\n" +
+		"Examples:\n" +
+		"  pping -s 10% google.com\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestHelpTextAtWidth(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.String("d", "", "DNS `server` IP address to use, e.g. /etc/resolv.conf.")
+	before := flags.HelpText()
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -d server  DNS server IP address to\n" +
+		"             use, e.g. /etc/resolv.conf.\n"
+	compare(t, exp, flags.HelpTextAtWidth(40))
+
+	exp = "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -d server  DNS server IP address to use, e.g.\n" +
+		"             /etc/resolv.conf.\n"
+	compare(t, exp, flags.HelpTextAtWidth(50))
+	compare(t, before, flags.HelpText())
+}
+
+func TestLineWidth(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.String("d", "", "DNS `server` IP address to use, e.g. /etc/resolv.conf.")
+
+	flags.LineWidth = 40
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -d server  DNS server IP address to\n" +
+		"             use, e.g. /etc/resolv.conf.\n"
+	compare(t, exp, flags.HelpText())
+
+	flags.LineWidth = 0
+	defer func(fn func() int) { terminalWidth = fn }(terminalWidth)
+	terminalWidth = func() int { return 50 }
+	exp = "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -d server  DNS server IP address to use, e.g.\n" +
+		"             /etc/resolv.conf.\n"
+	compare(t, exp, flags.HelpText())
+	// Generated files don't depend on the terminal
+	sections := flags.HelpSections()
+	compare(t, "  -d server  DNS server IP address to use, e.g. /etc/resolv.conf.", sections[len(sections)-1].Lines[1])
+}
+
+func TestDetectTerminalWidth(t *testing.T) {
+	if _, ok := ttyWidth(os.Stderr.Fd()); ok {
+		t.Skip("stderr is a terminal")
+	}
+	defer os.Setenv("COLUMNS", os.Getenv("COLUMNS"))
+	os.Setenv("COLUMNS", "100")
+	compare(t, 100, detectTerminalWidth())
+	os.Setenv("COLUMNS", "")
+	compare(t, 72, detectTerminalWidth())
+}
+
+func TestEffectiveWidth(t *testing.T) {
+	if _, ok := ttyWidth(os.Stderr.Fd()); ok {
+		t.Skip("stderr is a terminal")
+	}
+	defer func(fn func() int) { terminalWidth = fn }(terminalWidth)
+	terminalWidth = detectTerminalWidth
+	defer os.Setenv("COLUMNS", os.Getenv("COLUMNS"))
+
+	flags := NewFlags("pping", "", "", "", "help", false)
+	os.Setenv("COLUMNS", "")
+	compare(t, 72, flags.EffectiveWidth())
+	os.Setenv("COLUMNS", "100")
+	compare(t, 100, flags.EffectiveWidth())
+	flags.LineWidth = 60
+	compare(t, 60, flags.EffectiveWidth())
+}
+
+func TestWideCharacters(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.LineWidth = 40
+	flags.String("d", "", "`サーバー` 使用するDNSサーバーのIPアドレス。 これは名前解決に使用されます。")
+	flags.String("p", "tcp", "Specify `protocol` to use, e.g. tcp or udp.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -d サーバー  サーバー\n" +
+		"               使用するDNSサーバーのIPアドレス。\n" +
+		"               これは名前解決に使用されます。\n" +
+		"  -p protocol  Specify protocol to use,\n" +
+		"               e.g. tcp or udp.\n"
+	compare(t, exp, flags.HelpText())
+	compare(t, 4, textWidth("日本"))
+	compare(t, 4, textWidth("café"))
+}
+
+func TestColor(t *testing.T) {
+	// stderr, the flag set's output, is the terminal
+	defer func(fn func(io.Writer) bool) { isTerminal = fn }(isTerminal)
+	isTerminal = func(w io.Writer) bool { return w == os.Stderr }
+	defer os.Setenv("NO_COLOR", os.Getenv("NO_COLOR"))
+	os.Unsetenv("NO_COLOR")
+
+	flags := NewFlags("pping", "", "", "[options] host", "help", false)
+	flags.Color = true
+	flags.Int("c", 0, "Stop after sending specified `num`ber of pings, e.g. 100%.")
+	flags.Bool("w", false, "Wait for a response.")
+
+	exp := "\x1b[1mUsage:\x1b[0m pping [options] host\n" +
+		"\n" +
+		"\x1b[1mOptions:\x1b[0m\n" +
+		"  \x1b[36m-c\x1b[0m \x1b[33mnum\x1b[0m  Stop after sending specified number of pings, e.g. 100%.\n" +
+		"  \x1b[36m-w\x1b[0m      Wait for a response.\n"
+	compare(t, exp, flags.HelpText())
+	compare(t, exp, fmt.Sprintf(sanitize(flags.HelpText())))
+
+	os.Setenv("NO_COLOR", "1")
+	if got := flags.HelpText(); strings.Contains(got, "\x1b") {
+		t.Errorf("colors used with NO_COLOR:\n%q", got)
+	}
+	os.Unsetenv("NO_COLOR")
+	var buf bytes.Buffer
+	if err := flags.WriteHelp(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); strings.Contains(got, "\x1b") {
+		t.Errorf("colors written to a buffer:\n%q", got)
+	}
+	dir, err := ioutil.TempDir("", "niceflags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "pping.txt")
+	if err := flags.WriteHelpFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ioutil.ReadFile(path); bytes.Contains(got, []byte("\x1b")) {
+		t.Errorf("colors written to a file:\n%q", got)
+	}
+	flags.SetOutput(os.Stdout)
+	if got := flags.HelpText(); strings.Contains(got, "\x1b") {
+		t.Errorf("colors used when the output isn't a terminal:\n%q", got)
+	}
+}
+
+func TestWriteHelp(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Int("s", 64, "Payload `size` in bytes, at most 100% of the MTU.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -s size  Payload size in bytes, at most 100% of the MTU.\n"
+	var buf bytes.Buffer
+	if err := flags.WriteHelp(&buf); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, exp, buf.String())
+
+	buf.Reset()
+	flags.SetOutput(&buf)
+	flags.PrintHelp()
+	compare(t, exp, buf.String())
+}
+
+func TestHandleHelp(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	var buf bytes.Buffer
+	flags.SetOutput(&buf)
+
+	if err := flags.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, false, flags.HandleHelp())
+	compare(t, "", buf.String())
+
+	if err := flags.Parse([]string{"-help"}); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, true, flags.HandleHelp())
+	compare(t, flags.HelpText(), buf.String())
+}
+
+func TestGroup(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Int("c", 0, "Stop after sending specified `num`ber of pings.")
+	flags.String("d", "", "DNS `server` to use.")
+	flags.Int("port", 80, "Server `port` `default`.")
+	flags.Bool("v", false, "Verbose output.")
+	flags.Bool("json", false, "Print the results as JSON.")
+	flags.Group("Connection options", "port", "d")
+	flags.Group("Output options", "json")
+	flags.Group("Output options", "v")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -c    num     Stop after sending specified number of pings.\n" +
+		"\n" +
+		"Connection options:\n" +
+		"  -d    server  DNS server to use.\n" +
+		"  -port port    Server port (default=80).\n" +
+		"\n" +
+		"Output options:\n" +
+		"  -json         Print the results as JSON.\n" +
+		"  -v            Verbose output.\n"
+	compare(t, exp, flags.HelpText())
+
+	flags.Group("Ping options", "c")
+	if got := flags.HelpText(); strings.Contains(got, "Options:") {
+		t.Errorf("empty Options section is listed:\n%s", got)
+	}
+}
+
+func TestGroupHeading(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Bool("v", false, "Verbose output.")
+	flags.Bool("trace", false, "Trace the packets.")
+	flags.Group("100% legit options", "v")
+	flags.Group("Debugging options", "trace")
+	flags.Hide("trace")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"100% legit options:\n" +
+		"  -v   Verbose output.\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestHide(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Bool("w", false, "Wait for a response.")
+	debug := flags.Bool("debug", false, "Print debugging information.")
+	trace := flags.String("trace", "", "Write a trace to the `file`.")
+	flags.Hide("debug", "trace")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -w   Wait for a response.\n"
+	compare(t, exp, flags.HelpText())
+	compare(t, 1, flags.VisibleFlagCount())
+
+	if err := flags.Parse([]string{"-debug", "-trace", "out.trace"}); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, true, *debug)
+	compare(t, "out.trace", *trace)
+}
+
+func TestVersion(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	var buf bytes.Buffer
+	flags.SetOutput(&buf)
+	flags.EnableVersion("version", "pping 1.2.0")
+	flags.Bool("w", false, "Wait for a response.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -w   Wait for a response.\n"
+	compare(t, exp, flags.HelpText())
+
+	if err := flags.Parse([]string{"-w"}); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, false, flags.ShowVersion())
+	compare(t, "", buf.String())
+
+	if err := flags.Parse([]string{"-version"}); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, true, flags.ShowVersion())
+	compare(t, "pping 1.2.0\n", buf.String())
+}
+
+func TestSortCaseInsensitive(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Bool("a", false, "All interfaces.")
+	flags.Bool("Z", false, "Zero copy.")
+	flags.Bool("b", false, "Bind to the interface.")
+	flags.Bool("B", false, "Broadcast.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -B   Broadcast.\n" +
+		"  -Z   Zero copy.\n" +
+		"  -a   All interfaces.\n" +
+		"  -b   Bind to the interface.\n"
+	compare(t, exp, flags.HelpText())
+
+	flags.SortFlags = SortCaseInsensitive
+	exp = "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -a   All interfaces.\n" +
+		"  -B   Broadcast.\n" +
+		"  -b   Bind to the interface.\n" +
+		"  -Z   Zero copy.\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestExampleDesc(t *testing.T) {
+	flags := NewFlags("pping", "", "", "[options] host port", "help", false)
+	flags.Examples = []string{"google.com 80"}
+	flags.ExamplesDetailed = []Example{
+		{Command: "-p udp -w myserver.com 8085", Desc: "Pings a UDP server and waits for its responses, which " +
+			"requires the server to echo the packets back."},
+		{Command: "-c 5 google.com 443"},
+	}
+
+	exp := "Usage: pping [options] host port\n" +
+		"\n" +
+		"Options:\n" +
+		"\n" +
+		"Examples:\n" +
+		"  pping google.com 80\n" +
+		"  pping -p udp -w myserver.com 8085\n" +
+		"    Pings a UDP server and waits for its responses, which requires the\n" +
+		"    server to echo the packets back.\n" +
+		"  pping -c 5 google.com 443\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestIndent(t *testing.T) {
+	flags := NewFlags("pping", "", "Tool to simulate TCP and UDP pings. This can also be used as a port scanner.",
+		"[options] host port", "help", false)
+	flags.Indent = 4
+	flags.Examples = []string{"google.com 80"}
+	flags.ExitCodes = map[int]string{0: "All pings were answered."}
+	flags.Int("c", 0, "Stop after sending specified `num`ber of pings. Pings are sent once per second until "+
+		"the count is reached.")
+	flags.Bool("w", false, "Wait for a response.")
+
+	exp := "    Tool to simulate TCP and UDP pings. This can also be used as a port\n" +
+		"    scanner.\n" +
+		"\n" +
+		"Usage: pping [options] host port\n" +
+		"\n" +
+		"Options:\n" +
+		"    -c num  Stop after sending specified number of pings. Pings are sent\n" +
+		"            once per second until the count is reached.\n" +
+		"    -w      Wait for a response.\n" +
+		"\n" +
+		"Examples:\n" +
+		"    pping google.com 80\n" +
+		"\n" +
+		"Exit Status:\n" +
+		"    0  All pings were answered.\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestParamAfterDefault(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.String("x", "tcp", "`default` uses a `value`.")
+	flags.String("p", "", "Specify `protocol` to use.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -p protocol  Specify protocol to use.\n" +
+		"  -x value     (default=tcp) uses a value.\n"
+	compare(t, exp, flags.HelpText())
+
+	param, usage := extractParam("`default` uses a `value`.")
+	compare(t, "value", param)
+	compare(t, "`default` uses a value.", usage)
+
+	flags.DefaultInParam = true
+	if got := flags.HelpText(); !strings.Contains(got, "  -x value=tcp  uses a value.\n") {
+		t.Errorf("default isn't rendered in the parameter column:\n%s", got)
+	}
+}
+
+func TestRequiredFirst(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Bool("a", false, "All interfaces.")
+	flags.String("host", "", "Server `host`.")
+	flags.Bool("w", false, "Wait for a response.")
+	flags.Int("port", 0, "Server `port`.")
+	flags.Require("port", "host")
+	flags.RequiredFirst = true
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -host host  Server host. (required)\n" +
+		"  -port port  Server port. (required)\n" +
+		"  -a          All interfaces.\n" +
+		"  -w          Wait for a response.\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestHyperlinks(t *testing.T) {
+	defer func(fn func(io.Writer) bool) { isTerminal = fn }(isTerminal)
+	isTerminal = func(w io.Writer) bool { return w == os.Stderr }
+
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Hyperlinks = true
+	url := "https://example.com/docs/pping"
+	flags.String("p", "", "Specify `protocol` to use. See "+url+". Other protocols may be added later.")
+	link := "\x1b]8;;" + url + "\x1b\\" + url + "\x1b]8;;\x1b\\"
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -p protocol  Specify protocol to use. See\n" +
+		"               %s. Other protocols may be\n" +
+		"               added later.\n"
+	compare(t, fmt.Sprintf(exp, link), flags.HelpText())
+	compare(t, len(url), textWidth(link))
+
+	var buf bytes.Buffer
+	if err := flags.WriteHelp(&buf); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, fmt.Sprintf(exp, url), buf.String())
+}
+
 func compare(t *testing.T, exp, got interface{}) {
 	if exp != got {
 		t.Errorf("expected: %v, got: %v", exp, got)
@@ -0,0 +1,517 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UnknownFlagError is returned by Parse when a flag that hasn't been
+// defined is given on the command line.
+type UnknownFlagError struct {
+	// Flag is the name of the flag without the leading dashes.
+	Flag string
+}
+
+func (e *UnknownFlagError) Error() string {
+	return fmt.Sprintf("flag provided but not defined: -%s", e.Flag)
+}
+
+// InvalidValueError is returned by Parse when the value given to a flag
+// can't be set.
+type InvalidValueError struct {
+	// Flag is the name of the flag without the leading dashes.
+	Flag string
+
+	// Value is the value that was given to the flag.
+	Value string
+
+	// Cause is the error returned by the flag's Value.
+	Cause error
+}
+
+func (e *InvalidValueError) Error() string {
+	return fmt.Sprintf("invalid value %q for flag -%s: %v", e.Value, e.Flag, e.Cause)
+}
+
+// Unwrap returns the error returned by the flag's Value.
+func (e *InvalidValueError) Unwrap() error {
+	return e.Cause
+}
+
+// Parse parses flag definitions from the argument list, which should not
+// include the command name, just like flag.FlagSet.Parse. Once parsed,
+// the settings of any presets present on the command line are applied,
+// followed by the environment variables bound to the flags that are still
+// unset.
+//
+// Errors are handled as per the flag set's error handling mode. With
+// flag.ContinueOnError, an unknown flag is reported as an
+// *UnknownFlagError and a value that can't be set as an
+// *InvalidValueError.
+func (f *Flags) Parse(arguments []string) error {
+	var err error
+	if f.HelpOnNoArgs && len(arguments) == 0 {
+		f.PrintHelp()
+		err = flag.ErrHelp
+	} else {
+		err = f.parse(arguments)
+	}
+	if err == nil {
+		err = f.applyPresets()
+	}
+	if err == nil {
+		err = f.applyEnv()
+	}
+	if err == nil {
+		f.warn()
+		return nil
+	}
+	return f.fail(err)
+}
+
+// fail handles a parse error as per the flag set's error handling mode.
+// The error, other than flag.ErrHelp, is printed along with the usage.
+func (f *Flags) fail(err error) error {
+	if err != flag.ErrHelp {
+		fmt.Fprintln(f.Output(), err)
+		f.Usage()
+	}
+
+	switch f.ErrorHandling() {
+	case flag.ExitOnError:
+		if err == flag.ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	case flag.PanicOnError:
+		panic(err)
+	}
+	return err
+}
+
+// parse sets the flags given in arguments. It follows the same syntax as
+// the flag package: flag parsing stops just before the first non-flag
+// argument or after the terminator "--".
+func (f *Flags) parse(arguments []string) error {
+	f.passedThrough = nil
+	args := arguments
+	for len(args) > 0 {
+		s := args[0]
+		if len(s) < 2 || s[0] != '-' {
+			break
+		}
+		numMinuses := 1
+		if s[1] == '-' {
+			numMinuses++
+			if len(s) == 2 { // "--" terminates the flags
+				args = args[1:]
+				break
+			}
+		}
+		name := s[numMinuses:]
+		if len(name) == 0 || name[0] == '-' || name[0] == '=' {
+			return fmt.Errorf("bad flag syntax: %s", s)
+		}
+		args = args[1:]
+
+		hasValue := false
+		value := ""
+		if i := strings.Index(name, "="); i != -1 {
+			name, value, hasValue = name[:i], name[i+1:], true
+		}
+
+		fl := f.Lookup(name)
+		if fl == nil && f.PassthroughUnknown {
+			f.passedThrough = append(f.passedThrough, s)
+			if !hasValue && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+				f.passedThrough = append(f.passedThrough, args[0])
+				args = args[1:]
+			}
+			continue
+		}
+		if fl == nil {
+			if name == "help" || name == "h" {
+				f.Usage()
+				return flag.ErrHelp
+			}
+			return &UnknownFlagError{Flag: name}
+		}
+
+		if bv, ok := fl.Value.(boolFlag); ok && bv.IsBoolFlag() {
+			if !hasValue {
+				value = "true"
+			}
+		} else if !hasValue {
+			if len(args) == 0 {
+				return fmt.Errorf("flag needs an argument: -%s", name)
+			}
+			value, args = args[0], args[1:]
+		}
+		if err := f.Set(name, value); err != nil {
+			return &InvalidValueError{Flag: name, Value: value, Cause: err}
+		}
+		delete(f.implied, name)
+	}
+
+	// Hand the remaining arguments over to the flag set so that Args,
+	// NArg and Parsed work as usual.
+	return f.FlagSet.Parse(append([]string{"--"}, args...))
+}
+
+// PassedThrough returns the unknown flags, and their values, that were
+// collected by Parse when PassthroughUnknown is set, in the order in which
+// they were given.
+func (f *Flags) PassedThrough() []string {
+	return append([]string(nil), f.passedThrough...)
+}
+
+// boolFlag is implemented by flag values that don't need an argument,
+// just as in the flag package.
+type boolFlag interface {
+	flag.Value
+	IsBoolFlag() bool
+}
+
+// preset is a flag that sets other flags when present.
+type preset struct {
+	value *presetValue
+	sets  map[string]string
+}
+
+// presetValue is the flag.Value of a preset. It behaves like a bool flag.
+type presetValue struct {
+	name string
+	set  bool
+}
+
+func (p *presetValue) String() string {
+	if p == nil {
+		return "false"
+	}
+	return fmt.Sprintf("%v", p.set)
+}
+
+func (p *presetValue) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	p.set = v
+	return nil
+}
+
+func (p *presetValue) IsBoolFlag() bool { return true }
+
+// Preset registers a boolean flag which is a shorthand for setting other
+// flags, e.g. -fast could be a preset for "-level 9 -mode quick" with sets
+// being {"level": "9", "mode": "quick"}.
+// The settings are applied by Parse when the preset is present on the
+// command line. Flags that are explicitly given on the command line always
+// win over the values of a preset.
+func (f *Flags) Preset(name, usage string, sets map[string]string) {
+	v := &presetValue{name: name}
+	f.VarTracked(v, name, usage)
+	f.presets = append(f.presets, preset{v, sets})
+}
+
+// applyPresets applies the settings of the presets that were set, in the
+// order in which the presets were registered.
+func (f *Flags) applyPresets() error {
+	explicit := map[string]bool{}
+	f.Visit(func(fl *flag.Flag) {
+		explicit[fl.Name] = true
+	})
+
+	for _, p := range f.presets {
+		if !p.value.set {
+			continue
+		}
+		names := make([]string, 0, len(p.sets))
+		for name := range p.sets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if explicit[name] {
+				continue
+			}
+			if err := f.Set(name, p.sets[name]); err != nil {
+				return fmt.Errorf("preset -%s: %v", p.value.name, err)
+			}
+			f.imply(name)
+		}
+	}
+	return nil
+}
+
+// BindEnv binds a flag to an environment variable, whose value is used by
+// Parse if the flag isn't given on the command line (or set by a preset).
+// The variable is noted next to the flag in the help screen if
+// ShowEnvInline is set, and listed along with it in generated documents.
+func (f *Flags) BindEnv(flagName, envVar string) {
+	if f.env == nil {
+		f.env = map[string]string{}
+	}
+	f.env[flagName] = envVar
+}
+
+// EnvVar returns the environment variable bound to a flag with BindEnv, if
+// any.
+func (f *Flags) EnvVar(flagName string) (string, bool) {
+	envVar, ok := f.env[flagName]
+	return envVar, ok
+}
+
+// SetConfigKey documents the key under which the value of a flag can be
+// given in the application's configuration file, e.g. "server.host". It's
+// listed along with the flag in the man page, Markdown and
+// reStructuredText. niceflags doesn't read configuration files itself.
+func (f *Flags) SetConfigKey(flagName, key string) {
+	if f.configKeys == nil {
+		f.configKeys = map[string]string{}
+	}
+	f.configKeys[flagName] = key
+}
+
+// ConfigKey returns the configuration key of a flag set with SetConfigKey,
+// if any.
+func (f *Flags) ConfigKey(flagName string) (string, bool) {
+	key, ok := f.configKeys[flagName]
+	return key, ok
+}
+
+// applyEnv sets the unset flags bound to environment variables that are
+// set.
+func (f *Flags) applyEnv() error {
+	explicit := map[string]bool{}
+	f.Visit(func(fl *flag.Flag) {
+		explicit[fl.Name] = true
+	})
+
+	var err error
+	f.VisitAll(func(fl *flag.Flag) {
+		envVar, ok := f.env[fl.Name]
+		if !ok || explicit[fl.Name] || err != nil {
+			return
+		}
+		value, ok := os.LookupEnv(envVar)
+		if !ok {
+			return
+		}
+		if e := f.Set(fl.Name, value); e != nil {
+			err = fmt.Errorf("invalid value %q for flag %s from environment variable %s: %v", value, f.dash(fl.Name), envVar, e)
+			return
+		}
+		f.imply(fl.Name)
+	})
+	return err
+}
+
+// imply records that the flag was set by a preset or from the environment
+// rather than given on the command line.
+func (f *Flags) imply(name string) {
+	if f.implied == nil {
+		f.implied = map[string]bool{}
+	}
+	f.implied[name] = true
+}
+
+// Positional names the positional arguments expected after the flags, e.g.
+// "host" and "port", so that they can be fetched with NamedArg. Validate
+// checks that they are all given and, if RejectExtraArgs is set, that there
+// are no more.
+func (f *Flags) Positional(names ...string) {
+	f.positional = append(f.positional, names...)
+	if len(f.positional) > len(names) {
+		return // already validated
+	}
+	f.AddValidator(func() error {
+		n := len(f.positional)
+		if f.NArg() >= n && (!f.RejectExtraArgs || f.NArg() == n) {
+			return nil
+		}
+		plural := "s"
+		if n == 1 {
+			plural = ""
+		}
+		return fmt.Errorf("expected %d positional argument%s (%s), got %d",
+			n, plural, strings.Join(f.positional, ", "), f.NArg())
+	})
+}
+
+// NamedArg returns the positional argument declared with the given name by
+// Positional, or false if it wasn't given. (Arg can't be used since it's
+// taken by flag.FlagSet.)
+func (f *Flags) NamedArg(name string) (string, bool) {
+	for i, n := range f.positional {
+		if n == name && i < f.NArg() {
+			return f.Arg(i), true
+		}
+	}
+	return "", false
+}
+
+// PositionalString returns the i'th non-flag argument remaining after
+// Parse, or an error if there are fewer arguments.
+func (f *Flags) PositionalString(i int) (string, error) {
+	if i < 0 || i >= f.NArg() {
+		return "", fmt.Errorf("missing positional argument #%d", i+1)
+	}
+	return f.Arg(i), nil
+}
+
+// PositionalInt returns the i'th non-flag argument remaining after Parse
+// as an int, or an error if there are fewer arguments or if the argument
+// isn't an integer.
+func (f *Flags) PositionalInt(i int) (int, error) {
+	s, err := f.PositionalString(i)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid positional argument #%d %q: not an integer", i+1, s)
+	}
+	return n, nil
+}
+
+// Experimental marks a flag as experimental. Its description is annotated
+// with "(experimental)" in the help screen and Parse prints a warning,
+// followed by the optional message, to the flag set's output when the flag
+// is used.
+func (f *Flags) Experimental(name, message string) {
+	if f.experimental == nil {
+		f.experimental = map[string]string{}
+	}
+	f.experimental[name] = message
+}
+
+// Deprecate marks a flag as deprecated, e.g. when it's been renamed. Parse
+// prints a warning, followed by the optional message (e.g. "use -new
+// instead"), to the flag set's output the first time the flag is used. The
+// flag is either left out of the help screen, if hide is set, or its
+// description is annotated with "(deprecated)".
+func (f *Flags) Deprecate(flagName, message string, hide bool) {
+	if f.deprecated == nil {
+		f.deprecated = map[string]string{}
+	}
+	f.deprecated[flagName] = message
+	if hide {
+		f.Hide(flagName)
+	}
+}
+
+// warn prints warnings about the flags given on the command line. Flags set
+// by presets or from the environment aren't warned about.
+func (f *Flags) warn() {
+	warning := func(fl *flag.Flag, what, msg string) {
+		warning := fmt.Sprintf("warning: %s is %s", f.dash(fl.Name), what)
+		if msg != "" {
+			warning += "; " + msg
+		}
+		fmt.Fprintln(f.Output(), warning)
+	}
+	f.Visit(func(fl *flag.Flag) {
+		if f.implied[fl.Name] {
+			return
+		}
+		if msg, ok := f.experimental[fl.Name]; ok {
+			warning(fl, "experimental", msg)
+		}
+		if msg, ok := f.deprecated[fl.Name]; ok && !f.warnedDeprecated[fl.Name] {
+			if f.warnedDeprecated == nil {
+				f.warnedDeprecated = map[string]bool{}
+			}
+			f.warnedDeprecated[fl.Name] = true
+			warning(fl, "deprecated", msg)
+		}
+	})
+}
+
+// ParsedCommandLine reconstructs the command line from the flags that
+// were set and the remaining positional arguments, e.g.
+// "pping -c 35 -w host port". Flags are listed in lexicographical order
+// and values containing spaces or shell special characters are quoted.
+func (f *Flags) ParsedCommandLine() string {
+	args := []string{f.commandName()}
+	f.Visit(func(fl *flag.Flag) {
+		name := f.dash(fl.Name)
+		value := fl.Value.String()
+		if bv, ok := fl.Value.(boolFlag); ok && bv.IsBoolFlag() {
+			if value != "true" {
+				name += "=" + value
+			}
+			args = append(args, name)
+			return
+		}
+		args = append(args, name, shellQuote(value))
+	})
+	for _, arg := range f.Args() {
+		args = append(args, shellQuote(arg))
+	}
+	return strings.Join(args, " ")
+}
+
+// shellQuote quotes s for POSIX shells if needed.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`*?[]{}()<>|&;#~!") {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// ParseQuery sets flags from a URL-encoded query string, e.g.
+// "c=5&d=8.8.8.8&w", as if they were given on the command line. Boolean
+// flags without a value are set to true. Keys are applied in
+// lexicographical order and errors are returned as from Parse with
+// flag.ContinueOnError (i.e. regardless of the error handling mode). If
+// PassthroughUnknown is set, unknown keys are added to PassedThrough as
+// "-name=value", or "-name" if they have no value.
+func (f *Flags) ParseQuery(raw string) error {
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fl := f.Lookup(name)
+		if fl == nil && f.PassthroughUnknown {
+			for _, value := range values[name] {
+				if value == "" {
+					f.passedThrough = append(f.passedThrough, "-"+name)
+				} else {
+					f.passedThrough = append(f.passedThrough, "-"+name+"="+value)
+				}
+			}
+			continue
+		}
+		if fl == nil {
+			return &UnknownFlagError{Flag: name}
+		}
+		for _, value := range values[name] {
+			if bv, ok := fl.Value.(boolFlag); ok && bv.IsBoolFlag() && value == "" {
+				value = "true"
+			}
+			if err := f.Set(name, value); err != nil {
+				return &InvalidValueError{Flag: name, Value: value, Cause: err}
+			}
+			delete(f.implied, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,411 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPreset(t *testing.T) {
+	newFlags := func() (*Flags, *int, *string) {
+		flags := NewFlags("pping", "", "", "", "help", false)
+		level := flags.Int("level", 1, "Compression `level`.")
+		mode := flags.String("mode", "normal", "Compression `mode`.")
+		flags.Preset("fast", "Fastest settings.", map[string]string{"level": "9", "mode": "quick"})
+		return flags, level, mode
+	}
+
+	flags, level, mode := newFlags()
+	if err := flags.Parse([]string{"-fast"}); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, 9, *level)
+	compare(t, "quick", *mode)
+
+	// Explicit values win regardless of their position
+	flags, level, mode = newFlags()
+	if err := flags.Parse([]string{"-level", "3", "-fast"}); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, 3, *level)
+	compare(t, "quick", *mode)
+
+	// Presets are inert unless set
+	flags, level, mode = newFlags()
+	if err := flags.Parse([]string{"-mode", "slow"}); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, 1, *level)
+	compare(t, "slow", *mode)
+}
+
+func TestParseErrors(t *testing.T) {
+	newFlags := func() *Flags {
+		flags := NewFlags("pping", "", "", "", "help", false)
+		flags.Init("pping", flag.ContinueOnError)
+		flags.SetOutput(ioutil.Discard)
+		flags.Int("c", 0, "Stop after sending specified `num`ber of pings.")
+		return flags
+	}
+
+	err := newFlags().Parse([]string{"-c", "5", "-x"})
+	var unknown *UnknownFlagError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("expected an UnknownFlagError, got: %v", err)
+	}
+	compare(t, "x", unknown.Flag)
+	compare(t, "flag provided but not defined: -x", err.Error())
+
+	err = newFlags().Parse([]string{"-c", "five"})
+	var invalid *InvalidValueError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected an InvalidValueError, got: %v", err)
+	}
+	compare(t, "c", invalid.Flag)
+	compare(t, "five", invalid.Value)
+	if invalid.Cause == nil || errors.Unwrap(err) != invalid.Cause {
+		t.Errorf("expected the cause to be unwrapped, got: %v", errors.Unwrap(err))
+	}
+
+	flags := newFlags()
+	if err := flags.Parse([]string{"--c=5", "host", "-port"}); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, "5", flags.Lookup("c").Value.String())
+	compare(t, 2, flags.NArg())
+	compare(t, "-port", flags.Arg(1))
+	compare(t, true, flags.Parsed())
+}
+
+func TestHelpOnNoArgs(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Init("pping", flag.ContinueOnError)
+	flags.HelpOnNoArgs = true
+	flags.Int("c", 0, "")
+
+	compare(t, nil, flags.Parse([]string{"-c", "5"}))
+	compare(t, nil, flags.Parse([]string{"host"}))
+	compare(t, flag.ErrHelp, flags.Parse([]string{}))
+
+	flags.HelpOnNoArgs = false
+	compare(t, nil, flags.Parse(nil))
+}
+
+func TestPositional(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Int("c", 0, "")
+	if err := flags.Parse([]string{"-c", "5", "google.com", "80", "http"}); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+
+	host, err := flags.PositionalString(0)
+	compare(t, nil, err)
+	compare(t, "google.com", host)
+	port, err := flags.PositionalInt(1)
+	compare(t, nil, err)
+	compare(t, 80, port)
+
+	_, err = flags.PositionalInt(2)
+	compare(t, `invalid positional argument #3 "http": not an integer`, err.Error())
+	_, err = flags.PositionalString(3)
+	compare(t, "missing positional argument #4", err.Error())
+	_, err = flags.PositionalInt(-1)
+	compare(t, "missing positional argument #0", err.Error())
+}
+
+func TestExperimental(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	var out bytes.Buffer
+	flags.SetOutput(&out)
+	flags.Bool("new-engine", false, "Use the new engine.")
+	flags.Bool("w", false, "Wait for a response.")
+	flags.Experimental("new-engine", "it may change without notice")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -new-engine   Use the new engine. (experimental)\n" +
+		"  -w            Wait for a response.\n"
+	compare(t, exp, flags.HelpText())
+
+	if err := flags.Parse([]string{"-w"}); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, "", out.String())
+
+	if err := flags.Parse([]string{"-new-engine"}); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, "warning: -new-engine is experimental; it may change without notice\n", out.String())
+}
+
+func TestDeprecate(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	var out bytes.Buffer
+	flags.SetOutput(&out)
+	flags.Int("count", 0, "Stop after sending `num` pings.")
+	flags.Int("c", 0, "Stop after sending `num` pings.")
+	flags.Bool("wait", false, "Wait for a response.")
+	flags.Bool("w", false, "Wait for a response.")
+	flags.Deprecate("count", "use -c instead", false)
+	flags.Deprecate("wait", "use -w instead", true)
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -c     num  Stop after sending num pings.\n" +
+		"  -count num  Stop after sending num pings. (deprecated)\n" +
+		"  -w          Wait for a response.\n"
+	compare(t, exp, flags.HelpText())
+
+	if err := flags.Parse([]string{"-count", "3", "-wait"}); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, "warning: -count is deprecated; use -c instead\n"+
+		"warning: -wait is deprecated; use -w instead\n", out.String())
+
+	out.Reset()
+	if err := flags.Parse([]string{"-count", "3"}); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, "", out.String())
+}
+
+func TestParsedCommandLine(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Int("c", 0, "")
+	flags.String("d", "", "")
+	flags.String("m", "", "")
+	flags.Bool("w", false, "")
+	flags.Bool("x", true, "")
+	flags.Int("unset", 0, "")
+
+	args := []string{"-w", "-x=false", "-c", "35", "-m", "it's here", "-d", "8.8.8.8", "google.com", "80"}
+	if err := flags.Parse(args); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, `pping -c 35 -d 8.8.8.8 -m 'it'\''s here' -w -x=false google.com 80`, flags.ParsedCommandLine())
+}
+
+func TestPassthroughUnknown(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.Init("pping", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	flags.PassthroughUnknown = true
+	count := flags.Int("c", 0, "")
+
+	if err := flags.Parse(strings.Split("-x 1 -c 5 -y=2 -z -- host", " ")); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, 5, *count)
+	compare(t, "-x 1 -y=2 -z", strings.Join(flags.PassedThrough(), " "))
+	compare(t, "host", flags.Arg(0))
+
+	flags.PassthroughUnknown = false
+	if err := flags.Parse([]string{"-x", "1"}); err == nil {
+		t.Error("expected an error for an unknown flag")
+	}
+	compare(t, 0, len(flags.PassedThrough()))
+}
+
+func TestUsageOnError(t *testing.T) {
+	flags := NewFlags("pping", "", "", "[options] host port\nPings the host.", "help", false)
+	flags.Init("pping", flag.ContinueOnError)
+	var out bytes.Buffer
+	flags.SetOutput(&out)
+	flags.Int("c", 0, "")
+
+	compare(t, "Usage: pping [options] host port", flags.Synopsis())
+	if err := flags.Parse([]string{"-c", "five"}); err == nil {
+		t.Fatal("expected an error for an invalid value")
+	}
+	exp := "invalid value \"five\" for flag -c: parse error\n" +
+		"Usage: pping [options] host port\n" +
+		"See 'pping -help'\n"
+	compare(t, exp, out.String())
+}
+
+func TestParseQuery(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	count := flags.Int("c", 0, "")
+	dns := flags.String("d", "", "")
+	wait := flags.Bool("w", false, "")
+	verbose := flags.Bool("v", true, "")
+
+	if err := flags.ParseQuery("c=5&d=8.8.8.8&w&v=false"); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, 5, *count)
+	compare(t, "8.8.8.8", *dns)
+	compare(t, true, *wait)
+	compare(t, false, *verbose)
+
+	var unknown *UnknownFlagError
+	if err := flags.ParseQuery("x=1"); !errors.As(err, &unknown) {
+		t.Errorf("expected an UnknownFlagError, got: %v", err)
+	}
+	var invalid *InvalidValueError
+	if err := flags.ParseQuery("c=five"); !errors.As(err, &invalid) {
+		t.Errorf("expected an InvalidValueError, got: %v", err)
+	}
+}
+
+func TestParseQueryPassthrough(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.PassthroughUnknown = true
+	count := flags.Int("c", 0, "")
+
+	if err := flags.ParseQuery("c=5&x=1&y&x=2"); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, 5, *count)
+	compare(t, "-x=1 -x=2 -y", strings.Join(flags.PassedThrough(), " "))
+}
+
+func TestNewFlagsWithErrorHandling(t *testing.T) {
+	flags := NewFlagsWithErrorHandling("pping", "", "", "", "help", false, flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	compare(t, flag.ContinueOnError, flags.ErrorHandling())
+	var unknown *UnknownFlagError
+	if err := flags.Parse([]string{"-x"}); !errors.As(err, &unknown) {
+		t.Fatalf("expected unknown flag error, got %v", err)
+	}
+
+	flags = NewFlagsWithErrorHandling("pping", "", "", "", "help", false, flag.PanicOnError)
+	flags.SetOutput(ioutil.Discard)
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	flags.Parse([]string{"-x"})
+}
+
+func TestBindEnv(t *testing.T) {
+	defer os.Setenv("PPING_HOST", os.Getenv("PPING_HOST"))
+	defer os.Setenv("PPING_COUNT", os.Getenv("PPING_COUNT"))
+	os.Setenv("PPING_HOST", "example.com")
+	os.Setenv("PPING_COUNT", "5")
+
+	newFlags := func() (*Flags, *string, *int) {
+		flags := NewFlags("pping", "", "", "", "help", false)
+		flags.Init("pping", flag.ContinueOnError)
+		flags.SetOutput(ioutil.Discard)
+		host := flags.String("host", "localhost", "Server `host`.")
+		count := flags.Int("c", 1, "Stop after sending `num` pings.")
+		flags.Bool("w", false, "Wait for a response.")
+		flags.BindEnv("host", "PPING_HOST")
+		flags.BindEnv("c", "PPING_COUNT")
+		return flags, host, count
+	}
+
+	flags, host, count := newFlags()
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -c    num   Stop after sending num pings.\n" +
+		"  -host host  Server host.\n" +
+		"  -w          Wait for a response.\n"
+	compare(t, exp, flags.HelpText())
+
+	if err := flags.Parse([]string{"-c", "3"}); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, "example.com", *host)
+	compare(t, 3, *count)
+
+	os.Setenv("PPING_COUNT", "five")
+	flags, _, _ = newFlags()
+	err := flags.Parse(nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.HasPrefix(err.Error(), `invalid value "five" for flag -c from environment variable PPING_COUNT: `) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestEnvIsNotGiven(t *testing.T) {
+	defer os.Setenv("PPING_TCP", os.Getenv("PPING_TCP"))
+	os.Setenv("PPING_TCP", "true")
+
+	var out bytes.Buffer
+	flags := NewFlagsWithErrorHandling("pping", "", "", "", "help", false, flag.ContinueOnError)
+	flags.SetOutput(&out)
+	tcp := flags.Bool("tcp", false, "Use TCP.")
+	flags.Bool("udp", false, "Use UDP.")
+	flags.Int("level", 0, "Compression `level`.")
+	flags.Preset("fast", "Compress fast.", map[string]string{"level": "1"})
+	flags.BindEnv("tcp", "PPING_TCP")
+	flags.MutuallyExclusive("tcp", "udp")
+	flags.MutuallyExclusive("level", "udp")
+	flags.Experimental("tcp", "")
+	flags.Require("tcp")
+
+	if err := flags.Parse([]string{"-fast", "-udp"}); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, true, *tcp)
+	compare(t, nil, flags.Validate())
+	compare(t, "", out.String())
+
+	// The same flags given on the command line are checked
+	if err := flags.Parse([]string{"-tcp", "-udp"}); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, "flags -tcp and -udp are mutually exclusive", flags.Validate().Error())
+	compare(t, "warning: -tcp is experimental\n", out.String())
+}
+
+func TestShowEnvInline(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.String("host", "localhost", "Server `host`.")
+	flags.Int("c", 1, "Stop after sending `num` pings.")
+	flags.Bool("w", false, "Wait for a response.")
+	flags.BindEnv("host", "PPING_HOST")
+	flags.BindEnv("c", "PPING_COUNT")
+	flags.ShowEnvInline = true
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -c    num   Stop after sending num pings. [env: PPING_COUNT]\n" +
+		"  -host host  Server host. [env: PPING_HOST]\n" +
+		"  -w          Wait for a response.\n"
+	compare(t, exp, flags.HelpText())
+}
+
+func TestNamedPositional(t *testing.T) {
+	flags := NewFlags("pping", "", "", "[options] host port", "help", false)
+	flags.Bool("w", false, "Wait for a response.")
+	flags.Positional("host", "port")
+
+	if err := flags.Parse([]string{"-w", "example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, "expected 2 positional arguments (host, port), got 1", flags.Validate().Error())
+	host, ok := flags.NamedArg("host")
+	compare(t, "example.com", host)
+	compare(t, true, ok)
+	_, ok = flags.NamedArg("port")
+	compare(t, false, ok)
+
+	if err := flags.Parse([]string{"example.com", "80", "extra"}); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, nil, flags.Validate())
+	port, _ := flags.NamedArg("port")
+	compare(t, "80", port)
+
+	flags.RejectExtraArgs = true
+	compare(t, "expected 2 positional arguments (host, port), got 3", flags.Validate().Error())
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// rstEscaper escapes the characters that start inline markup in
+// reStructuredText.
+var rstEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"`", "\\`",
+	"_", `\_`,
+	"|", `\|`,
+)
+
+// RST returns the help screen as reStructuredText, suitable for Sphinx
+// documentation. The flags are described with the standard program and
+// option directives and the usage and examples are rendered as literal
+// blocks. Descriptions aren't wrapped since the documentation renderer
+// reflows them anyway. If OmitCommandName is set, the program directive is
+// left out along with the command name.
+func (f *Flags) RST() string {
+	var buf bytes.Buffer
+
+	write := func(msg string, args ...interface{}) {
+		buf.WriteString(fmt.Sprintf(msg, args...))
+	}
+
+	literal := func(lines []string) {
+		write("::\n\n")
+		for _, l := range lines {
+			write("   %s\n", l)
+		}
+		write("\n")
+	}
+
+	// Title
+	if f.Title != "" {
+		title := rstEscaper.Replace(f.Title)
+		write("%s\n%s\n\n", title, strings.Repeat("=", utf8.RuneCountInString(title)))
+	}
+
+	// Description
+	if f.Description != "" {
+		write("%s\n\n", rstEscaper.Replace(stripNowrap(unescapeNewlines(f.Description))))
+	}
+
+	// Command usage
+	usageTokens := strings.Split(unescapeNewlines(f.UsageOptions), "\n")
+	write("Usage")
+	literal([]string{f.withCmdName(usageTokens[0])})
+	if l := len(usageTokens); l > 1 {
+		write("%s\n\n", rstEscaper.Replace(strings.Join(usageTokens[1:l], "\n")))
+	}
+
+	// Options
+	if !f.OmitCommandName {
+		write(".. program:: %s\n\n", f.commandName())
+	}
+	f.VisitAll(func(fl *flag.Flag) {
+		if f.skip(fl) {
+			return
+		}
+		param, usage := f.describe(f.longFlag(fl))
+		write(".. option:: %s", f.dash(fl.Name))
+		if param != "" {
+			write(" <%s>", param)
+		}
+		write("\n\n")
+		for _, l := range strings.Split(unescapeNewlines(usage), "\n") {
+			write("   %s\n", rstEscaper.Replace(l))
+		}
+		if envVar, ok := f.EnvVar(fl.Name); ok {
+			write("\n   Environment variable: ``%s``\n", envVar)
+		}
+		if key, ok := f.ConfigKey(fl.Name); ok {
+			write("\n   Config key: ``%s``\n", key)
+		}
+		write("\n")
+	})
+
+	// Examples
+	// Consecutive examples without a description share a literal block and
+	// the description of an example follows its own block.
+	if examples := f.examples(); len(examples) > 0 {
+		write("Examples")
+		var lines []string
+		for _, e := range examples {
+			lines = append(lines, f.withCmdName(unescapeNewlines(e.Command)))
+			if e.Desc != "" {
+				literal(lines)
+				lines = nil
+				write("%s\n\n", rstEscaper.Replace(stripNowrap(unescapeNewlines(e.Desc))))
+			}
+		}
+		if len(lines) > 0 {
+			literal(lines)
+		}
+	}
+
+	return strings.TrimRight(buf.String(), "\n") + "\n"
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRST(t *testing.T) {
+	flags := NewFlags("pping", "pping - Protocol Ping", "Tool to simulate *TCP* pings.", "[options] host port", "help", false)
+	flags.Examples = []string{"-s 128 google.com 80"}
+	flags.Int("s", 64, "Payload `size` in bytes `default`.")
+	flags.Bool("w", false, "Wait for a_response.")
+
+	got := flags.RST()
+	for _, exp := range []string{
+		"pping - Protocol Ping\n=====================\n\n",
+		"Tool to simulate \\*TCP\\* pings.\n",
+		"Usage::\n\n   pping [options] host port\n",
+		".. program:: pping\n",
+		".. option:: -s <size>\n\n   Payload size in bytes (default=64).\n",
+		".. option:: -w\n\n   Wait for a\\_response.\n",
+		"Examples::\n\n   pping -s 128 google.com 80\n",
+	} {
+		if !strings.Contains(got, exp) {
+			t.Errorf("RST doesn't contain %q:\n%s", exp, got)
+		}
+	}
+	if strings.Contains(got, "-help") {
+		t.Errorf("RST describes the help flag:\n%s", got)
+	}
+}
+
+func TestRSTOmitCommandName(t *testing.T) {
+	flags := NewFlags("pping", "", "", "[options] host port", "help", false)
+	flags.OmitCommandName = true
+	flags.Examples = []string{"-s 128 google.com 80"}
+	flags.Int("s", 64, "Payload `size` in bytes `default`.")
+
+	got := flags.RST()
+	for _, exp := range []string{
+		"Usage::\n\n   [options] host port\n",
+		"Examples::\n\n   -s 128 google.com 80\n",
+	} {
+		if !strings.Contains(got, exp) {
+			t.Errorf("RST doesn't contain %q:\n%s", exp, got)
+		}
+	}
+	if strings.Contains(got, "pping") {
+		t.Errorf("RST contains the command name:\n%s", got)
+	}
+}
+
+func TestRSTExamplesDetailed(t *testing.T) {
+	flags := NewFlags("pping", "", "", "host", "help", false)
+	flags.Examples = []string{"google.com"}
+	flags.ExamplesDetailed = []Example{
+		{Command: "-s 128 google.com", Desc: "Ping with a *larger* payload."},
+		{Command: "-p udp google.com", Tags: []string{"advanced"}},
+		{Command: "-c 3 google.com", Tags: []string{"basic"}},
+	}
+	flags.ShowExampleTags = []string{"advanced"}
+
+	exp := "Examples::\n" +
+		"\n" +
+		"   pping google.com\n" +
+		"   pping -s 128 google.com\n" +
+		"\n" +
+		"Ping with a \\*larger\\* payload.\n" +
+		"\n" +
+		"::\n" +
+		"\n" +
+		"   pping -p udp google.com\n"
+	if got := flags.RST(); !strings.HasSuffix(got, exp) {
+		t.Errorf("RST doesn't end with %q:\n%s", exp, got)
+	}
+}
+
+func TestRSTEnvAndConfigKey(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.String("host", "localhost", "Server `host`.")
+	flags.BindEnv("host", "PPING_HOST")
+	flags.SetConfigKey("host", "server.host")
+
+	exp := ".. option:: -host <host>\n\n" +
+		"   Server host.\n\n" +
+		"   Environment variable: ``PPING_HOST``\n\n" +
+		"   Config key: ``server.host``\n"
+	if got := flags.RST(); !strings.Contains(got, exp) {
+		t.Errorf("RST doesn't contain %q:\n%s", exp, got)
+	}
+}
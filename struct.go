@@ -0,0 +1,152 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StructVars defines a flag for every field of the struct pointed to by
+// ptr that has a niceflag tag, of the form `niceflag:"name,usage"`. The
+// field holds the value of the flag and its current value is the flag's
+// default. Fields may be of type bool, int, int64, uint, uint64, float64,
+// string or time.Duration. Flag names can't contain commas but usages can,
+// e.g. `niceflag:"c,Stop after sending specified number of pings."`.
+//
+// Fields may also have a validate tag listing comma-separated rules that
+// Validate checks, e.g. `validate:"min=1,max=9"`. See RegisterTagValidator
+// for the available validators.
+func (f *Flags) StructVars(ptr interface{}) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return errors.New("niceflags: StructVars expects a pointer to a struct")
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("niceflag")
+		if !ok {
+			continue
+		}
+		if field.PkgPath != "" {
+			return fmt.Errorf("niceflags: field %s is not exported", field.Name)
+		}
+		name, usage := tag, ""
+		if i := strings.Index(tag, ","); i != -1 {
+			name, usage = tag[:i], tag[i+1:]
+		}
+		if name == "" {
+			return fmt.Errorf("niceflags: field %s has no flag name", field.Name)
+		}
+
+		switch p := v.Field(i).Addr().Interface().(type) {
+		case *bool:
+			f.BoolVar(p, name, *p, usage)
+		case *int:
+			f.IntVar(p, name, *p, usage)
+		case *int64:
+			f.Int64Var(p, name, *p, usage)
+		case *uint:
+			f.UintVar(p, name, *p, usage)
+		case *uint64:
+			f.Uint64Var(p, name, *p, usage)
+		case *float64:
+			f.Float64Var(p, name, *p, usage)
+		case *string:
+			f.StringVar(p, name, *p, usage)
+		case *time.Duration:
+			f.DurationVar(p, name, *p, usage)
+		default:
+			return fmt.Errorf("niceflags: field %s has unsupported type %s", field.Name, field.Type)
+		}
+		f.track(name)
+
+		if rules, ok := field.Tag.Lookup("validate"); ok {
+			if err := f.tagValidations(name, rules); err != nil {
+				return fmt.Errorf("niceflags: field %s: %v", field.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// TagValidator validates the value of a flag as per the parameter given in
+// a validate tag, e.g. "tcp udp" for `validate:"oneof=tcp udp"`. It returns
+// an error describing why the value is invalid.
+type TagValidator func(value, param string) error
+
+// tagValidators holds the validators available to validate tags.
+var tagValidators = map[string]TagValidator{
+	"oneof": func(value, param string) error {
+		allowed := strings.Fields(param)
+		for _, a := range allowed {
+			if value == a {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+	},
+	"min": func(value, param string) error {
+		return compareNumber(value, param, "at least", func(n, limit float64) bool { return n >= limit })
+	},
+	"max": func(value, param string) error {
+		return compareNumber(value, param, "at most", func(n, limit float64) bool { return n <= limit })
+	},
+}
+
+// RegisterTagValidator makes a validator available to the validate tags of
+// the structs given to StructVars, in addition to the built-in "oneof"
+// (space-separated allowed values), "min" and "max" (numeric limits).
+// Registering a validator with an existing name replaces it.
+func RegisterTagValidator(name string, fn TagValidator) {
+	tagValidators[name] = fn
+}
+
+// compareNumber checks that value is a number satisfying ok against the
+// number in param.
+func compareNumber(value, param, desc string, ok func(n, limit float64) bool) error {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return fmt.Errorf("invalid limit %q", param)
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return errors.New("must be a number")
+	}
+	if !ok(n, limit) {
+		return fmt.Errorf("must be %s %s", desc, param)
+	}
+	return nil
+}
+
+// tagValidations registers a validation, run by Validate, for each of the
+// comma-separated rules (e.g. "min=1,max=9") of a validate tag.
+func (f *Flags) tagValidations(name, rules string) error {
+	for _, rule := range strings.Split(rules, ",") {
+		vName, param := rule, ""
+		if i := strings.Index(rule, "="); i != -1 {
+			vName, param = rule[:i], rule[i+1:]
+		}
+		fn, ok := tagValidators[vName]
+		if !ok {
+			return fmt.Errorf("unknown validator %q", vName)
+		}
+		f.AddValidator(func() error {
+			if err := fn(f.Lookup(name).Value.String(), param); err != nil {
+				return fmt.Errorf("invalid value for flag %s: %v", f.dash(name), err)
+			}
+			return nil
+		})
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStructVars(t *testing.T) {
+	cfg := struct {
+		Count    int           `niceflag:"c,Stop after sending specified number of pings, if any."`
+		DNS      string        `niceflag:"d,DNS server IP address to use."`
+		Wait     bool          `niceflag:"w,Wait for a response."`
+		Timeout  time.Duration `niceflag:"t,Max time-to-live."`
+		Protocol string        `niceflag:"p"`
+		Ignored  int
+	}{Count: 10, Protocol: "tcp", Timeout: time.Second}
+
+	flags := NewFlags("pping", "", "", "", "help", false)
+	if err := flags.StructVars(&cfg); err != nil {
+		t.Fatal("error when defining flags", err)
+	}
+	compare(t, "help,c,d,w,t,p", strings.Join(flags.Declared(), ","))
+	compare(t, "Stop after sending specified number of pings, if any.", flags.Lookup("c").Usage)
+	compare(t, "10", flags.Lookup("c").DefValue)
+	compare(t, "tcp", flags.Lookup("p").DefValue)
+
+	if err := flags.Parse(strings.Split("-c 35 -d 8.8.8.8 -w -t 2s", " ")); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, 35, cfg.Count)
+	compare(t, "8.8.8.8", cfg.DNS)
+	compare(t, true, cfg.Wait)
+	compare(t, 2*time.Second, cfg.Timeout)
+	compare(t, "tcp", cfg.Protocol)
+
+	// Invalid input
+	compare(t, "niceflags: StructVars expects a pointer to a struct", flags.StructVars(cfg).Error())
+	bad := struct {
+		Ratio float32 `niceflag:"r"`
+	}{}
+	compare(t, "niceflags: field Ratio has unsupported type float32", flags.StructVars(&bad).Error())
+}
+
+func TestStructValidators(t *testing.T) {
+	newFlags := func() *Flags {
+		cfg := struct {
+			Protocol string `niceflag:"p,Protocol." validate:"oneof=tcp udp"`
+			Count    int    `niceflag:"c,Count." validate:"min=1,max=100"`
+		}{Protocol: "tcp", Count: 1}
+		flags := NewFlags("pping", "", "", "", "help", false)
+		if err := flags.StructVars(&cfg); err != nil {
+			t.Fatal("error when defining flags", err)
+		}
+		return flags
+	}
+
+	for _, c := range []struct {
+		args string
+		err  interface{}
+	}{
+		{"-p udp -c 5", nil},
+		{"-p tpc -c 0", "invalid value for flag -p: must be one of tcp, udp\n" +
+			"invalid value for flag -c: must be at least 1"},
+		{"-c 101", "invalid value for flag -c: must be at most 100"},
+	} {
+		flags := newFlags()
+		if err := flags.Parse(strings.Split(c.args, " ")); err != nil {
+			t.Fatal("error when parsing", err)
+		}
+		err := flags.Validate()
+		if err == nil {
+			compare(t, c.err, nil)
+		} else {
+			compare(t, c.err, err.Error())
+		}
+	}
+
+	// Custom and unknown validators
+	RegisterTagValidator("even", func(value, _ string) error {
+		if n, _ := strconv.Atoi(value); n%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	})
+	defer delete(tagValidators, "even")
+	cfg := struct {
+		Size int    `niceflag:"s" validate:"even"`
+		Name string `niceflag:"n" validate:"nonsense"`
+	}{Size: 3}
+	flags := NewFlags("pping", "", "", "", "help", false)
+	compare(t, `niceflags: field Name: unknown validator "nonsense"`, flags.StructVars(&cfg).Error())
+	compare(t, "invalid value for flag -s: must be even", flags.Validate().Error())
+}
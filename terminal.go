@@ -0,0 +1,106 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// terminalWidth returns the width of the terminal the help screen is
+// printed to. It's a variable so that tests don't depend on the terminal
+// they're run in.
+var terminalWidth = detectTerminalWidth
+
+// detectTerminalWidth returns the width of the terminal attached to
+// stderr, where the help screen is printed. If stderr isn't a terminal,
+// the COLUMNS environment variable is used and failing that, the default
+// line length.
+func detectTerminalWidth() int {
+	if w, ok := ttyWidth(os.Stderr.Fd()); ok && w > 0 {
+		return w
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return maxLineLength
+}
+
+// EffectiveWidth returns the width HelpText wraps the help text to: the
+// LineWidth if set, or else the detected width of the terminal, the COLUMNS
+// environment variable or the default of 72 columns, in that order.
+func (f *Flags) EffectiveWidth() int {
+	return f.lineWidth()
+}
+
+// lineWidth returns the width the help text is wrapped to.
+func (f *Flags) lineWidth() int {
+	if f.LineWidth > 0 {
+		return f.LineWidth
+	}
+	return terminalWidth()
+}
+
+// fileWidth returns the width generated help text, e.g. saved to a file, is
+// wrapped to. Unlike lineWidth, it doesn't depend on the terminal.
+func (f *Flags) fileWidth() int {
+	if f.LineWidth > 0 {
+		return f.LineWidth
+	}
+	return maxLineLength
+}
+
+// isTerminal returns true if w is a terminal. It's a variable so that
+// tests don't depend on the terminal they're run in.
+var isTerminal = func(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	_, ok = ttyWidth(file.Fd())
+	return ok
+}
+
+// ANSI escape codes used by Color.
+const (
+	colorHeader = "\x1b[1m"
+	colorFlag   = "\x1b[36m"
+	colorParam  = "\x1b[33m"
+	colorReset  = "\x1b[0m"
+)
+
+// color wraps s in the escape codes of the color if colors are enabled and
+// ansi is set, i.e. the text is written to a terminal.
+func (f *Flags) color(ansi bool, code, s string) string {
+	if !f.Color || !ansi || s == "" || os.Getenv("NO_COLOR") != "" {
+		return s
+	}
+	return code + s + colorReset
+}
+
+// link wraps the URLs of s in OSC 8 escape sequences, so that terminals
+// render them as clickable links, if Hyperlinks is enabled and ansi is set.
+// Punctuation ending a sentence isn't part of the link.
+func (f *Flags) link(ansi bool, s string) string {
+	if !f.Hyperlinks || !ansi {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	for i, ln := range lines {
+		words := strings.Split(ln, " ")
+		for j, w := range words {
+			if !isURL(w) {
+				continue
+			}
+			url := strings.TrimRight(w, ".,;:!?)")
+			words[j] = "\x1b]8;;" + url + "\x1b\\" + url + "\x1b]8;;\x1b\\" + w[len(url):]
+		}
+		lines[i] = strings.Join(words, " ")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,15 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package niceflags
+
+// ttyWidth can't tell the width of terminals on this platform, so the
+// COLUMNS environment variable or the default line length are used.
+func ttyWidth(fd uintptr) (int, bool) {
+	return 0, false
+}
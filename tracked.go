@@ -0,0 +1,92 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"flag"
+	"time"
+)
+
+// The *Tracked functions define flags just like their counterparts in the
+// flag package (e.g. BoolTracked is flag.FlagSet.Bool) but also record
+// the flag in niceflags' own registries, such as the declaration order
+// returned by Declared. Flags defined directly on the embedded FlagSet
+// are still rendered, but niceflags knows nothing more about them than
+// what the flag package offers.
+
+// Declared returns the names of the flags defined with the *Tracked
+// functions, in the order in which they were defined. The help flag is
+// always first.
+func (f *Flags) Declared() []string {
+	return append([]string(nil), f.order...)
+}
+
+func (f *Flags) track(name string) {
+	f.order = append(f.order, name)
+}
+
+// VarTracked defines a flag with a custom value. See flag.FlagSet.Var.
+func (f *Flags) VarTracked(value flag.Value, name, usage string) {
+	f.Var(value, name, usage)
+	f.track(name)
+}
+
+// BoolTracked defines a bool flag. See flag.FlagSet.Bool.
+func (f *Flags) BoolTracked(name string, value bool, usage string) *bool {
+	p := f.Bool(name, value, usage)
+	f.track(name)
+	return p
+}
+
+// IntTracked defines an int flag. See flag.FlagSet.Int.
+func (f *Flags) IntTracked(name string, value int, usage string) *int {
+	p := f.Int(name, value, usage)
+	f.track(name)
+	return p
+}
+
+// Int64Tracked defines an int64 flag. See flag.FlagSet.Int64.
+func (f *Flags) Int64Tracked(name string, value int64, usage string) *int64 {
+	p := f.Int64(name, value, usage)
+	f.track(name)
+	return p
+}
+
+// UintTracked defines a uint flag. See flag.FlagSet.Uint.
+func (f *Flags) UintTracked(name string, value uint, usage string) *uint {
+	p := f.Uint(name, value, usage)
+	f.track(name)
+	return p
+}
+
+// Uint64Tracked defines a uint64 flag. See flag.FlagSet.Uint64.
+func (f *Flags) Uint64Tracked(name string, value uint64, usage string) *uint64 {
+	p := f.Uint64(name, value, usage)
+	f.track(name)
+	return p
+}
+
+// StringTracked defines a string flag. See flag.FlagSet.String.
+func (f *Flags) StringTracked(name string, value string, usage string) *string {
+	p := f.String(name, value, usage)
+	f.track(name)
+	return p
+}
+
+// Float64Tracked defines a float64 flag. See flag.FlagSet.Float64.
+func (f *Flags) Float64Tracked(name string, value float64, usage string) *float64 {
+	p := f.Float64(name, value, usage)
+	f.track(name)
+	return p
+}
+
+// DurationTracked defines a time.Duration flag. See
+// flag.FlagSet.Duration.
+func (f *Flags) DurationTracked(name string, value time.Duration, usage string) *time.Duration {
+	p := f.Duration(name, value, usage)
+	f.track(name)
+	return p
+}
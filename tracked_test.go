@@ -0,0 +1,53 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTracked(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	size := flags.IntTracked("s", 64, "Payload `size` in bytes.")
+	proto := flags.StringTracked("p", "tcp", "Specify `protocol` to use.")
+	wait := flags.BoolTracked("w", false, "Wait for a response.")
+	flags.DurationTracked("t", time.Second, "Max `time`-to-live.")
+	flags.VarTracked(&unitValue{}, "u", "Custom value.")
+	flags.Preset("fast", "Fastest settings.", map[string]string{"s": "8"})
+	flags.Int("direct", 0, "Not tracked.")
+
+	compare(t, "help,s,p,w,t,u,fast", strings.Join(flags.Declared(), ","))
+
+	// Tracked flags work just like the ones defined directly
+	if err := flags.Parse([]string{"-s", "128", "-p", "udp", "-w"}); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, 128, *size)
+	compare(t, "udp", *proto)
+	compare(t, true, *wait)
+}
+
+func TestSortDeclared(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	flags.SortFlags = SortDeclared
+	flags.StringTracked("host", "", "Server `host`.")
+	flags.IntTracked("port", 0, "Server `port`.")
+	flags.Bool("w", false, "Wait for a response.")
+	flags.IntTracked("c", 0, "Stop after sending `num` pings.")
+	flags.Bool("a", false, "All interfaces.")
+
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -host host  Server host.\n" +
+		"  -port port  Server port.\n" +
+		"  -c    num   Stop after sending num pings.\n" +
+		"  -a          All interfaces.\n" +
+		"  -w          Wait for a response.\n"
+	compare(t, exp, flags.HelpText())
+}
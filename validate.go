@@ -0,0 +1,339 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ValidationErrors is returned by Validate when one or more validations
+// fail. It holds the error of every failed validation.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// AddValidator registers a validation to be run by Validate. fn is
+// expected to inspect the parsed flags and return an error describing
+// the problem if they're invalid.
+func (f *Flags) AddValidator(fn func() error) {
+	f.validators = append(f.validators, fn)
+}
+
+// Validate runs all the registered validations, in the order in which
+// they were registered, and must be called after Parse. Every validation
+// is run even if an earlier one fails, so that all problems are reported
+// at once as ValidationErrors.
+func (f *Flags) Validate() error {
+	var errs ValidationErrors
+	if err := f.CheckRequired(); err != nil {
+		errs = append(errs, err)
+	}
+	for _, fn := range f.validators {
+		if err := fn(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Require marks the flags as required. They are annotated with
+// "(required)" in the help screen and checked by CheckRequired, which is
+// also run by Validate.
+func (f *Flags) Require(names ...string) {
+	f.required = append(f.required, names...)
+}
+
+// CheckRequired returns an error listing the required flags that weren't
+// given on the command line, nor set by a preset or from the environment.
+// It must be called after Parse.
+func (f *Flags) CheckRequired() error {
+	var missing []string
+	for _, name := range f.required {
+		if !f.hasValue(name) {
+			missing = append(missing, f.dash(name))
+		}
+	}
+	switch len(missing) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("missing required flag: %s", missing[0])
+	}
+	return fmt.Errorf("missing required flags: %s", strings.Join(missing, ", "))
+}
+
+// isRequired returns true if the flag was marked as required.
+func (f *Flags) isRequired(name string) bool {
+	for _, r := range f.required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireIf makes the flag required when the predicate holds, e.g. when
+// another flag has a certain value. The predicate is evaluated by Validate,
+// after parsing, so it can inspect the parsed values. Since the predicate
+// is opaque, the error lists the flags that were given on the command line
+// to explain why the flag is required.
+func (f *Flags) RequireIf(name string, when func() bool) {
+	f.AddValidator(func() error {
+		if !when() || f.hasValue(name) {
+			return nil
+		}
+		var given []string
+		f.Visit(func(fl *flag.Flag) {
+			if f.isSet(fl.Name) {
+				given = append(given, fmt.Sprintf("%s=%s", f.dash(fl.Name), fl.Value))
+			}
+		})
+		if len(given) == 0 {
+			return fmt.Errorf("flag %s is required", f.dash(name))
+		}
+		return fmt.Errorf("flag %s is required when given %s", f.dash(name), strings.Join(given, " "))
+	})
+}
+
+// Together makes Validate check that the flags are given together, i.e.
+// either all or none of them, e.g. a certificate and its key. Only the flags
+// given on the command line are checked, not those set by presets or from
+// the environment.
+func (f *Flags) Together(names ...string) {
+	f.AddValidator(func() error {
+		var given, missing []string
+		for _, name := range names {
+			if f.isSet(name) {
+				given = append(given, f.dash(name))
+			} else {
+				missing = append(missing, f.dash(name))
+			}
+		}
+		if len(given) == 0 || len(missing) == 0 {
+			return nil
+		}
+		return fmt.Errorf("flags %s must be given together with %s", strings.Join(missing, ", "), strings.Join(given, ", "))
+	})
+}
+
+// MutuallyExclusive makes Validate check that at most one of the flags is
+// given, e.g. -tcp and -udp. The flags are annotated with the others in the
+// help screen. It can be called for several groups. Only the flags given on
+// the command line are checked, not those set by presets or from the
+// environment.
+func (f *Flags) MutuallyExclusive(group ...string) {
+	f.exclusive = append(f.exclusive, group)
+	f.AddValidator(func() error {
+		var given []string
+		for _, name := range group {
+			if f.isSet(name) {
+				given = append(given, f.dash(name))
+			}
+		}
+		if len(given) < 2 {
+			return nil
+		}
+		last := len(given) - 1
+		return fmt.Errorf("flags %s and %s are mutually exclusive", strings.Join(given[:last], ", "), given[last])
+	})
+}
+
+// exclusiveWith returns the flags that can't be given with the flag.
+func (f *Flags) exclusiveWith(name string) []string {
+	var others []string
+	for _, group := range f.exclusive {
+		for _, n := range group {
+			if n == name {
+				for _, o := range group {
+					if o != name {
+						others = append(others, f.dash(o))
+					}
+				}
+				break
+			}
+		}
+	}
+	return others
+}
+
+// isSet returns true if the flag was given on the command line, as opposed
+// to set by a preset or from the environment.
+func (f *Flags) isSet(name string) bool {
+	return f.hasValue(name) && !f.implied[name]
+}
+
+// hasValue returns true if the flag was set, whether on the command line, by
+// a preset or from the environment.
+func (f *Flags) hasValue(name string) bool {
+	set := false
+	f.Visit(func(fl *flag.Flag) {
+		if fl.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// RequirePositive makes Validate check that the numeric flags are
+// greater than zero. It works with any flag whose value is a number, such
+// as int and float64 flags.
+func (f *Flags) RequirePositive(names ...string) {
+	f.requireNumber(names, "positive", func(n float64) bool { return n > 0 })
+}
+
+// RequireNonNegative makes Validate check that the numeric flags are not
+// negative. It works with any flag whose value is a number, such as int and
+// float64 flags.
+func (f *Flags) RequireNonNegative(names ...string) {
+	f.requireNumber(names, "non-negative", func(n float64) bool { return n >= 0 })
+}
+
+// requireNumber registers a validation for each of the flags, checking that
+// its value is a number satisfying ok.
+func (f *Flags) requireNumber(names []string, desc string, ok func(float64) bool) {
+	for _, name := range names {
+		name := name
+		f.AddValidator(func() error {
+			fl := f.Lookup(name)
+			if fl == nil {
+				return fmt.Errorf("flag %s is not defined", f.dash(name))
+			}
+			n, err := strconv.ParseFloat(fl.Value.String(), 64)
+			if err != nil {
+				return fmt.Errorf("flag %s must be a number, got %s", f.dash(name), fl.Value)
+			}
+			if !ok(n) {
+				return fmt.Errorf("flag %s must be %s, got %s", f.dash(name), desc, fl.Value)
+			}
+			return nil
+		})
+	}
+}
+
+// RequireURL makes Validate check that the flags, when not empty, hold
+// absolute URLs such as "https://example.com/path".
+func (f *Flags) RequireURL(names ...string) {
+	f.requireValue(names, func(value string) error {
+		u, err := url.Parse(value)
+		if err != nil {
+			return err
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%q is not an absolute URL", value)
+		}
+		return nil
+	})
+}
+
+// RequireHostPort makes Validate check that the flags, when not empty, hold
+// a host and port such as "example.com:80" or "[::1]:80".
+func (f *Flags) RequireHostPort(names ...string) {
+	f.requireValue(names, func(value string) error {
+		host, port, err := net.SplitHostPort(value)
+		if err != nil {
+			return err
+		}
+		if host == "" {
+			return fmt.Errorf("%q has no host", value)
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+			return fmt.Errorf("%q has an invalid port", value)
+		}
+		return nil
+	})
+}
+
+// requireValue registers a validation for each of the flags, checking its
+// value, if not empty, with check.
+func (f *Flags) requireValue(names []string, check func(value string) error) {
+	for _, name := range names {
+		name := name
+		f.AddValidator(func() error {
+			fl := f.Lookup(name)
+			if fl == nil {
+				return fmt.Errorf("flag %s is not defined", f.dash(name))
+			}
+			value := fl.Value.String()
+			if value == "" {
+				return nil
+			}
+			if err := check(value); err != nil {
+				return fmt.Errorf("invalid value for flag %s: %v", f.dash(name), err)
+			}
+			return nil
+		})
+	}
+}
+
+// LintExamples parses every example through a throwaway copy of the flag
+// set and returns an error for each example that references unknown flags
+// or fails to parse. Examples are split on white space, so quoted values
+// spanning several words aren't supported. The values given to flags
+// defined with custom values (i.e. with Var) aren't checked. The flag set
+// itself isn't modified.
+func (f *Flags) LintExamples() []error {
+	examples := append([]string(nil), f.Examples...)
+	for _, ex := range f.ExamplesDetailed {
+		examples = append(examples, ex.Command)
+	}
+
+	var errs []error
+	for _, ex := range examples {
+		fs := flag.NewFlagSet(f.Name(), flag.ContinueOnError)
+		fs.SetOutput(ioutil.Discard)
+		f.VisitAll(func(fl *flag.Flag) {
+			fs.Var(copyValue(fl), fl.Name, fl.Usage)
+		})
+		tmp := &Flags{FlagSet: fs, PassthroughUnknown: f.PassthroughUnknown}
+		if err := tmp.parse(strings.Fields(ex)); err != nil {
+			errs = append(errs, fmt.Errorf("example %q: %v", ex, err))
+		}
+	}
+	return errs
+}
+
+// copyValue returns a copy of a flag value that can be set without
+// affecting the original. Only the values of the flag package, which hold
+// the flag's variable itself, and those of enum flags can be copied safely;
+// other values may point to the flag's variable, so they are replaced by
+// one that accepts anything.
+func copyValue(fl *flag.Flag) flag.Value {
+	if e, ok := fl.Value.(*enumValue); ok {
+		return &enumValue{new(string), e.allowed}
+	}
+	if flagType(fl) != "" {
+		rv := reflect.ValueOf(fl.Value)
+		c := reflect.New(rv.Elem().Type())
+		c.Elem().Set(rv.Elem())
+		return c.Interface().(flag.Value)
+	}
+	bv, ok := fl.Value.(boolFlag)
+	return lenientValue(ok && bv.IsBoolFlag())
+}
+
+// lenientValue is a flag value that accepts anything. It's a boolean flag
+// if it's true.
+type lenientValue bool
+
+func (v lenientValue) String() string   { return "" }
+func (v lenientValue) Set(string) error { return nil }
+func (v lenientValue) IsBoolFlag() bool { return bool(v) }
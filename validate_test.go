@@ -0,0 +1,322 @@
+// Copyright (c) 2018 codeliveroil. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package niceflags
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	count := flags.Int("c", 1, "")
+	proto := flags.String("p", "tcp", "")
+
+	var order []string
+	flags.AddValidator(func() error {
+		order = append(order, "c")
+		if *count < 1 {
+			return errors.New("-c must be at least 1")
+		}
+		return nil
+	})
+	flags.AddValidator(func() error {
+		order = append(order, "p")
+		if *proto != "tcp" && *proto != "udp" {
+			return errors.New("-p must be tcp or udp")
+		}
+		return nil
+	})
+
+	if err := flags.Parse([]string{"-c", "5"}); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, nil, flags.Validate())
+	compare(t, "c,p", order[0]+","+order[1])
+
+	if err := flags.Parse([]string{"-c", "0", "-p", "icmp"}); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	err := flags.Validate()
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors, got: %v", err)
+	}
+	compare(t, 2, len(errs))
+	compare(t, "-c must be at least 1\n-p must be tcp or udp", err.Error())
+}
+
+func TestRequireIf(t *testing.T) {
+	newFlags := func() *Flags {
+		flags := NewFlags("pping", "", "", "", "help", false)
+		proto := flags.String("p", "tcp", "")
+		flags.String("d", "", "")
+		flags.RequireIf("d", func() bool { return *proto == "custom" })
+		return flags
+	}
+
+	for _, c := range []struct {
+		args string
+		err  interface{}
+	}{
+		{"-p tcp", nil},
+		{"-p custom -d 8.8.8.8", nil},
+		{"-p custom", "flag -d is required when given -p=custom"},
+	} {
+		flags := newFlags()
+		if err := flags.Parse(strings.Split(c.args, " ")); err != nil {
+			t.Fatal("error when parsing", err)
+		}
+		err := flags.Validate()
+		if err == nil {
+			compare(t, c.err, nil)
+		} else {
+			compare(t, c.err, err.Error())
+		}
+	}
+}
+
+func TestRequireIfImplied(t *testing.T) {
+	defer os.Setenv("PPING_PROTOCOL", os.Getenv("PPING_PROTOCOL"))
+	os.Setenv("PPING_PROTOCOL", "custom")
+
+	flags := NewFlags("pping", "", "", "", "help", false)
+	proto := flags.String("p", "tcp", "")
+	flags.String("d", "", "")
+	flags.Bool("w", false, "")
+	flags.BindEnv("p", "PPING_PROTOCOL")
+	flags.RequireIf("d", func() bool { return *proto == "custom" })
+
+	if err := flags.Parse([]string{"-w"}); err != nil {
+		t.Fatal("error when parsing", err)
+	}
+	compare(t, "flag -d is required when given -w=true", flags.Validate().Error())
+}
+
+func TestRequirePositive(t *testing.T) {
+	for _, c := range []struct {
+		args string
+		err  interface{}
+	}{
+		{"-c 5 -r 0.5", nil},
+		{"-c 0 -r 0", "flag -c must be positive, got 0"},
+		{"-c -3 -r -0.5", "flag -c must be positive, got -3\nflag -r must be non-negative, got -0.5"},
+	} {
+		flags := NewFlags("pping", "", "", "", "help", false)
+		flags.Int("c", 1, "")
+		flags.Float64("r", 0, "")
+		flags.RequirePositive("c")
+		flags.RequireNonNegative("r")
+		if err := flags.Parse(strings.Split(c.args, " ")); err != nil {
+			t.Fatal("error when parsing", err)
+		}
+		err := flags.Validate()
+		if err == nil {
+			compare(t, c.err, nil)
+		} else {
+			compare(t, c.err, err.Error())
+		}
+	}
+}
+
+func TestRequireURLAndHostPort(t *testing.T) {
+	for _, c := range []struct {
+		args []string
+		err  interface{}
+	}{
+		{[]string{}, nil},
+		{[]string{"-u", "https://example.com/docs", "-a", "example.com:80"}, nil},
+		{[]string{"-a", "[::1]:8080"}, nil},
+		{[]string{"-u", "example.com/docs"}, `invalid value for flag -u: "example.com/docs" is not an absolute URL`},
+		{[]string{"-u", "http://exa mple.com"}, `invalid value for flag -u: parse "http://exa mple.com": invalid character " " in host name`},
+		{[]string{"-a", "example.com"}, "invalid value for flag -a: address example.com: missing port in address"},
+		{[]string{"-a", "example.com:http"}, `invalid value for flag -a: "example.com:http" has an invalid port`},
+		{[]string{"-a", ":80"}, `invalid value for flag -a: ":80" has no host`},
+	} {
+		flags := NewFlags("pping", "", "", "", "help", false)
+		flags.String("u", "", "")
+		flags.String("a", "", "")
+		flags.RequireURL("u")
+		flags.RequireHostPort("a")
+		if err := flags.Parse(c.args); err != nil {
+			t.Fatal("error when parsing", err)
+		}
+		err := flags.Validate()
+		if err == nil {
+			compare(t, c.err, nil)
+		} else {
+			compare(t, c.err, err.Error())
+		}
+	}
+}
+
+func TestLintExamples(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	c := flags.Int("c", 3, "Stop after sending specified `num`ber of pings.")
+	flags.Bool("w", false, "Wait for a response from the server.")
+	flags.Examples = []string{
+		"-c 5 -w google.com 80",
+		"-s 128 google.com 80",
+	}
+	flags.ExamplesDetailed = []Example{{Command: "-c five google.com 80"}}
+
+	errs := flags.LintExamples()
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	compare(t, `example "-s 128 google.com 80": flag provided but not defined: -s`, errs[0].Error())
+	if !strings.HasPrefix(errs[1].Error(), `example "-c five google.com 80": invalid value "five" for flag -c`) {
+		t.Errorf("unexpected error: %v", errs[1])
+	}
+	compare(t, 3, *c)
+	compare(t, false, flags.Parsed())
+}
+
+// hostsValue is a flag.Value that appends to a slice it points to, like
+// values bound to a variable of the caller.
+type hostsValue struct {
+	hosts *[]string
+}
+
+func (h hostsValue) String() string {
+	if h.hosts == nil {
+		return ""
+	}
+	return strings.Join(*h.hosts, ",")
+}
+
+func (h hostsValue) Set(s string) error {
+	*h.hosts = append(*h.hosts, s)
+	return nil
+}
+
+func TestLintExamplesKeepsValues(t *testing.T) {
+	flags := NewFlags("pping", "", "", "", "help", false)
+	p := flags.Enum("p", "tcp", []string{"tcp", "udp"}, "Use the `protocol`.")
+	var hosts []string
+	flags.Var(hostsValue{&hosts}, "d", "Resolve with the DNS `server`.")
+	flags.Examples = []string{
+		"-p udp -d 8.8.8.8 google.com 80",
+		"-p icmp google.com 80",
+	}
+
+	errs := flags.LintExamples()
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+	if !strings.HasPrefix(errs[0].Error(), `example "-p icmp google.com 80": invalid value "icmp" for flag -p`) {
+		t.Errorf("unexpected error: %v", errs[0])
+	}
+	compare(t, "tcp", *p)
+	compare(t, 0, len(hosts))
+}
+
+func TestTogether(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		err  string
+	}{
+		{nil, ""},
+		{[]string{"-cert", "a.pem", "-key", "a.key", "-ca", "ca.pem"}, ""},
+		{[]string{"-cert", "a.pem"}, "flags -key, -ca must be given together with -cert"},
+	} {
+		flags := NewFlags("pping", "", "", "", "help", false)
+		flags.String("cert", "", "TLS `certificate` file.")
+		flags.String("key", "", "TLS `key` file.")
+		flags.String("ca", "", "TLS `CA` file.")
+		flags.Together("cert", "key", "ca")
+		if err := flags.Parse(tc.args); err != nil {
+			t.Fatal(err)
+		}
+
+		err := flags.Validate()
+		if tc.err == "" {
+			compare(t, nil, err)
+			continue
+		}
+		if err == nil {
+			t.Fatalf("expected error for %v", tc.args)
+		}
+		compare(t, tc.err, err.Error())
+	}
+}
+
+func TestRequire(t *testing.T) {
+	newFlags := func() *Flags {
+		flags := NewFlags("pping", "", "", "", "help", false)
+		flags.String("host", "", "Server `host`.")
+		flags.Int("port", 0, "Server `port`.")
+		flags.Bool("w", false, "Wait for a response.")
+		flags.Require("host", "port")
+		return flags
+	}
+
+	flags := newFlags()
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -host host  Server host. (required)\n" +
+		"  -port port  Server port. (required)\n" +
+		"  -w          Wait for a response.\n"
+	compare(t, exp, flags.HelpText())
+
+	if err := flags.Parse([]string{"-w"}); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, "missing required flags: -host, -port", flags.CheckRequired().Error())
+	compare(t, "missing required flags: -host, -port", flags.Validate().Error())
+
+	flags = newFlags()
+	if err := flags.Parse([]string{"-port", "80"}); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, "missing required flag: -host", flags.CheckRequired().Error())
+
+	flags = newFlags()
+	if err := flags.Parse([]string{"-host", "example.com", "-port", "80"}); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, nil, flags.CheckRequired())
+}
+
+func TestMutuallyExclusive(t *testing.T) {
+	newFlags := func() *Flags {
+		flags := NewFlags("pping", "", "", "", "help", false)
+		flags.Bool("tcp", false, "Use TCP.")
+		flags.Bool("udp", false, "Use UDP.")
+		flags.Bool("icmp", false, "Use ICMP.")
+		flags.Bool("q", false, "Quiet output.")
+		flags.Bool("v", false, "Verbose output.")
+		flags.MutuallyExclusive("tcp", "udp", "icmp")
+		flags.MutuallyExclusive("q", "v")
+		return flags
+	}
+
+	flags := newFlags()
+	exp := "Usage: pping \n" +
+		"\n" +
+		"Options:\n" +
+		"  -icmp   Use ICMP. (mutually exclusive with -tcp, -udp)\n" +
+		"  -q      Quiet output. (mutually exclusive with -v)\n" +
+		"  -tcp    Use TCP. (mutually exclusive with -udp, -icmp)\n" +
+		"  -udp    Use UDP. (mutually exclusive with -tcp, -icmp)\n" +
+		"  -v      Verbose output. (mutually exclusive with -q)\n"
+	compare(t, exp, flags.HelpText())
+
+	if err := flags.Parse([]string{"-tcp", "-v"}); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, nil, flags.Validate())
+
+	flags = newFlags()
+	if err := flags.Parse([]string{"-tcp", "-udp", "-icmp", "-q", "-v"}); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, "flags -tcp, -udp and -icmp are mutually exclusive\n"+
+		"flags -q and -v are mutually exclusive", flags.Validate().Error())
+}